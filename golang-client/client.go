@@ -0,0 +1,39 @@
+package wyron_client
+
+import (
+	"context"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/grpc"
+	"github.com/wyronapp/wyron-public/golang-client/internal/wgconfig"
+	"github.com/wyronapp/wyron-public/golang-client/rest"
+)
+
+// Peer is a peer resolved against its interface, the shape both
+// rest.NormalizePeer and grpc.NormalizePeer return.
+type Peer = wgconfig.Peer
+
+// Client is the part of the API both transports expose with the same
+// signatures, for code that shouldn't care which one it talks to.
+type Client interface {
+	Login(ctx context.Context) error
+	IsTokenValid(ctx context.Context) (bool, error)
+	Preflight(ctx context.Context) error
+	TokenExpiry() time.Time
+	ParseTokenClaims() (map[string]any, error)
+
+	MetricValue(ctx context.Context, name string) (float64, error)
+	FindDuplicateSocialIDs(ctx context.Context) (map[int64][]string, error)
+
+	EnableUsersBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]error, error)
+	DisableUsersBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]error, error)
+	DeleteUsersBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]error, error)
+	ResetUsageBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]error, error)
+
+	Close() error
+}
+
+var (
+	_ Client = (*rest.Client)(nil)
+	_ Client = (*grpc.Client)(nil)
+)
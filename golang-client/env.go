@@ -0,0 +1,121 @@
+package wyron_client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/grpc"
+	"github.com/wyronapp/wyron-public/golang-client/rest"
+)
+
+// NewFromEnv builds a client from the environment:
+//
+//	WYRON_BASE_URL   REST base URL, or the gRPC host:port; for gRPC an
+//	                 https:// URL turns on TLS, and an http(s):// URL
+//	                 without a port uses 80 or 443 (required)
+//	WYRON_USERNAME   (required)
+//	WYRON_PASSWORD   (required)
+//	WYRON_PROXY      proxy URL (optional)
+//	WYRON_TIMEOUT    duration such as "15s", or whole seconds (optional)
+//	WYRON_TRANSPORT  "rest" (default) or "grpc"
+func NewFromEnv() (Client, error) {
+	env, err := readEnv()
+	if err != nil {
+		return nil, err
+	}
+	if env.grpc != nil {
+		c, err := grpc.NewClient(*env.grpc)
+		if err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	c, err := rest.NewClientFromConfig(*env.rest)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// envConfig is the environment read by NewFromEnv; exactly one of rest and
+// grpc is set, per WYRON_TRANSPORT.
+type envConfig struct {
+	rest *rest.Config
+	grpc *grpc.Config
+}
+
+func readEnv() (envConfig, error) {
+	var missing []string
+	get := func(name string) string {
+		v := os.Getenv(name)
+		if v == "" {
+			missing = append(missing, name)
+		}
+		return v
+	}
+	baseURL := get("WYRON_BASE_URL")
+	username := get("WYRON_USERNAME")
+	password := get("WYRON_PASSWORD")
+	if len(missing) > 0 {
+		return envConfig{}, fmt.Errorf("missing environment variable %s", strings.Join(missing, ", "))
+	}
+	proxyURL := os.Getenv("WYRON_PROXY")
+
+	var timeout time.Duration
+	if v := os.Getenv("WYRON_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			secs, serr := strconv.Atoi(v)
+			if serr != nil {
+				return envConfig{}, fmt.Errorf("invalid WYRON_TIMEOUT %q: %w", v, err)
+			}
+			d = time.Duration(secs) * time.Second
+		}
+		if d < 0 {
+			return envConfig{}, fmt.Errorf("invalid WYRON_TIMEOUT %q: negative", v)
+		}
+		timeout = d
+	}
+
+	switch transport := strings.ToLower(os.Getenv("WYRON_TRANSPORT")); transport {
+	case "", "rest":
+		return envConfig{rest: &rest.Config{
+			BaseURL:  baseURL,
+			Username: username,
+			Password: password,
+			ProxyURL: proxyURL,
+			Timeout:  timeout,
+		}}, nil
+	case "grpc":
+		cfg := &grpc.Config{
+			Host:     baseURL,
+			Username: username,
+			Password: password,
+			ProxyURL: proxyURL,
+			Timeout:  timeout,
+		}
+		if u, err := url.Parse(baseURL); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			if u.Hostname() == "" {
+				return envConfig{}, errors.New("invalid WYRON_BASE_URL: no host")
+			}
+			cfg.Secure = u.Scheme == "https"
+			port := u.Port()
+			if port == "" {
+				port = "80"
+				if cfg.Secure {
+					port = "443"
+				}
+			}
+			cfg.Host = net.JoinHostPort(u.Hostname(), port)
+		}
+		return envConfig{grpc: cfg}, nil
+	default:
+		return envConfig{}, fmt.Errorf("invalid WYRON_TRANSPORT %q: want rest or grpc", transport)
+	}
+}
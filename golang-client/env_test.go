@@ -0,0 +1,118 @@
+package wyron_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest"
+)
+
+func TestReadEnv(t *testing.T) {
+	base := map[string]string{
+		"WYRON_BASE_URL": "https://panel.example.com/api",
+		"WYRON_USERNAME": "u",
+		"WYRON_PASSWORD": "p",
+	}
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string // substring; empty expects success
+
+		wantTimeout time.Duration
+		wantHost    string // gRPC only
+		wantSecure  bool
+	}{
+		{name: "missing", env: map[string]string{"WYRON_BASE_URL": "", "WYRON_USERNAME": "", "WYRON_PASSWORD": "p"},
+			wantErr: "WYRON_BASE_URL, WYRON_USERNAME"},
+		{name: "rest defaults", env: nil},
+		{name: "duration timeout", env: map[string]string{"WYRON_TIMEOUT": "1m30s"}, wantTimeout: 90 * time.Second},
+		{name: "seconds timeout", env: map[string]string{"WYRON_TIMEOUT": "20"}, wantTimeout: 20 * time.Second},
+		{name: "negative timeout", env: map[string]string{"WYRON_TIMEOUT": "-5s"}, wantErr: "WYRON_TIMEOUT"},
+		{name: "negative seconds", env: map[string]string{"WYRON_TIMEOUT": "-5"}, wantErr: "WYRON_TIMEOUT"},
+		{name: "bad timeout", env: map[string]string{"WYRON_TIMEOUT": "soon"}, wantErr: "WYRON_TIMEOUT"},
+		{name: "bad transport", env: map[string]string{"WYRON_TRANSPORT": "soap"}, wantErr: "WYRON_TRANSPORT"},
+		{name: "grpc https", env: map[string]string{"WYRON_TRANSPORT": "grpc"},
+			wantHost: "panel.example.com:443", wantSecure: true},
+		{name: "grpc https port", env: map[string]string{"WYRON_TRANSPORT": "GRPC", "WYRON_BASE_URL": "https://panel.example.com:8443"},
+			wantHost: "panel.example.com:8443", wantSecure: true},
+		{name: "grpc http", env: map[string]string{"WYRON_TRANSPORT": "grpc", "WYRON_BASE_URL": "http://10.0.0.1"},
+			wantHost: "10.0.0.1:80"},
+		{name: "grpc host port", env: map[string]string{"WYRON_TRANSPORT": "grpc", "WYRON_BASE_URL": "10.0.0.1:9090"},
+			wantHost: "10.0.0.1:9090"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{"WYRON_BASE_URL", "WYRON_USERNAME", "WYRON_PASSWORD", "WYRON_PROXY", "WYRON_TIMEOUT", "WYRON_TRANSPORT"} {
+				t.Setenv(k, base[k])
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			env, err := readEnv()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want one naming %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantHost == "" {
+				if env.rest == nil || env.grpc != nil {
+					t.Fatalf("got %+v, want a REST config", env)
+				}
+				if env.rest.Timeout != tt.wantTimeout {
+					t.Errorf("Timeout = %v, want %v", env.rest.Timeout, tt.wantTimeout)
+				}
+				return
+			}
+			if env.grpc == nil || env.rest != nil {
+				t.Fatalf("got %+v, want a gRPC config", env)
+			}
+			if env.grpc.Host != tt.wantHost || env.grpc.Secure != tt.wantSecure {
+				t.Errorf("Host, Secure = %q, %v; want %q, %v", env.grpc.Host, env.grpc.Secure, tt.wantHost, tt.wantSecure)
+			}
+		})
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": "tok"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer srv.Close()
+
+	t.Setenv("WYRON_BASE_URL", srv.URL)
+	t.Setenv("WYRON_USERNAME", "u")
+	t.Setenv("WYRON_PASSWORD", "")
+	if _, err := NewFromEnv(); err == nil || !strings.Contains(err.Error(), "WYRON_PASSWORD") {
+		t.Fatalf("err = %v, want one naming WYRON_PASSWORD", err)
+	}
+
+	t.Setenv("WYRON_PASSWORD", "p")
+	t.Setenv("WYRON_TIMEOUT", "5")
+	c, err := NewFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.(*rest.Client); !ok {
+		t.Fatalf("NewFromEnv() = %T, want *rest.Client", c)
+	}
+	defer c.Close()
+	if ok, err := c.IsTokenValid(context.Background()); err != nil || !ok {
+		t.Fatalf("IsTokenValid() = %v, %v; want true", ok, err)
+	}
+}
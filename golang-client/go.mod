@@ -2,12 +2,16 @@ module github.com/wyronapp/wyron-public/golang-client
 
 go 1.25.6
 
-require google.golang.org/grpc v1.79.1
+require (
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	golang.org/x/net v0.48.0
+	golang.org/x/time v0.14.0
+	google.golang.org/grpc v1.79.1
+	google.golang.org/protobuf v1.36.10
+)
 
 require (
-	golang.org/x/net v0.48.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20251202230838-ff82c1b0f217 // indirect
-	google.golang.org/protobuf v1.36.10 // indirect
 )
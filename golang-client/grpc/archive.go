@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteConfigArchive streams a zip of every peer config of the given users to
+// w. Users or peers that fail are skipped and reported in the returned error;
+// the archive written so far stays valid when ctx is canceled.
+func (c *Client) WriteConfigArchive(ctx context.Context, w io.Writer, userKeys []string, opts ConfigOptions) error {
+	zw := zip.NewWriter(w)
+	r := c.newServerResolver()
+	used := make(map[string]int)
+	var errs []error
+
+	for _, key := range userKeys {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		user, err := c.GetUser(ctx, key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", key, err))
+			continue
+		}
+
+		confs, err := c.userConfigs(ctx, r, used, user, opts)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, conf := range confs {
+			f, err := zw.Create(conf.FileName)
+			if err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+			if _, err := io.WriteString(f, conf.Config); err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func archiveName(used map[string]int, base string) string {
+	base = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, base)
+
+	// used counts the names handed out per base. A suffixed name can also
+	// arrive as a base ("a", "a", "a-2"), so skip suffixes already taken.
+	n, name := used[base], base
+	for {
+		n++
+		if n > 1 {
+			name = base + "-" + strconv.Itoa(n)
+		}
+		if used[name] == 0 {
+			break
+		}
+	}
+	used[base] = n
+	used[name] = max(used[name], 1)
+	return name + ".conf"
+}
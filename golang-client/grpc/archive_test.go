@@ -0,0 +1,29 @@
+package grpc
+
+import "testing"
+
+func TestArchiveNameUnique(t *testing.T) {
+	tests := []struct {
+		bases []string
+		want  []string
+	}{
+		{[]string{"a", "a", "a"}, []string{"a.conf", "a-2.conf", "a-3.conf"}},
+		{[]string{"a", "a", "a-2"}, []string{"a.conf", "a-2.conf", "a-2-2.conf"}},
+		{[]string{"a-2", "a", "a"}, []string{"a-2.conf", "a.conf", "a-3.conf"}},
+		{[]string{"a b", "a_b"}, []string{"a_b.conf", "a_b-2.conf"}},
+	}
+	for _, tt := range tests {
+		used := make(map[string]int)
+		seen := make(map[string]bool)
+		for i, base := range tt.bases {
+			got := archiveName(used, base)
+			if got != tt.want[i] {
+				t.Errorf("%q: name %d = %q, want %q", tt.bases, i, got, tt.want[i])
+			}
+			if seen[got] {
+				t.Errorf("%q: %q handed out twice", tt.bases, got)
+			}
+			seen[got] = true
+		}
+	}
+}
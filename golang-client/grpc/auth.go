@@ -2,36 +2,120 @@ package grpc
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"github.com/wyronapp/wyron-public/golang-client/internal/relogin"
+	"github.com/wyronapp/wyron-public/golang-client/internal/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// Login fetches a token for the configured credentials, or for those set on
+// ctx with WithCredentials.
 func (c *Client) Login(ctx context.Context) error {
 	c.loginMu.Lock()
 	defer c.loginMu.Unlock()
 
+	return c.login(ctx)
+}
+
+const maxLoginBackoff = 10 * time.Second
+
+// initialLogin is Login retried up to MaxLoginRetries times while the
+// backend is not ready yet.
+func (c *Client) initialLogin(ctx context.Context) error {
+	backoff := c.cfg.LoginRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := c.Login(ctx)
+		if err == nil || attempt >= c.cfg.MaxLoginRetries || status.Code(err) != codes.Unavailable {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxLoginBackoff)
+	}
+}
+
+func (c *Client) refreshIfExpiring(ctx context.Context) error {
+	if !c.needsLogin(ctx) {
+		return nil
+	}
+
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	// another caller may have refreshed while we waited
+	if !c.needsLogin(ctx) {
+		return nil
+	}
+	c.stats.totalRelogins.Add(1)
+	return c.login(ctx)
+}
+
+// reloginAfter replaces the token used, which the server rejected. Calls
+// rejected together share one login: whoever comes second finds the token
+// already replaced and just retries with it.
+func (c *Client) reloginAfter(ctx context.Context, used string) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if tok, _ := c.session(ctx); tok != used {
+		return nil
+	}
+	if !c.relogins.Allow() {
+		return ErrAuthLoop
+	}
+	c.stats.totalRelogins.Add(1)
+	if err := c.login(ctx); err != nil {
+		return err
+	}
+	return relogin.Wait(ctx, c.cfg.ReloginDelay)
+}
+
+// needsLogin reports whether the token for ctx is expiring, or missing
+// because a tenant set through WithCredentials hasn't logged in yet.
+func (c *Client) needsLogin(ctx context.Context) bool {
+	tok, exp := c.session(ctx)
+	return tok == "" || token.Expiring(exp, c.cfg.RefreshAhead)
+}
+
+func (c *Client) login(ctx context.Context) error {
 	if _, ok := ctx.Deadline(); !ok {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
 		defer cancel()
 	}
 
+	username, password := c.credentials(ctx)
 	res, err := c.auth.Login(ctx, &pb.LoginRequest{
-		Username: c.cfg.Username,
-		Password: c.cfg.Password,
+		Username: username,
+		Password: password,
 	})
 	if err != nil {
+		switch status.Code(err) {
+		case codes.Unauthenticated, codes.PermissionDenied, codes.InvalidArgument:
+			return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+		}
 		return err
 	}
+	if res.GetToken() == "" {
+		return ErrLoginNoToken
+	}
 
-	c.setToken(res.GetToken())
+	c.setSession(ctx, res.GetToken())
 	return nil
 }
 
-func (c *Client) Me() (string, error) {
+func (c *Client) Me(ctx context.Context) (string, error) {
 	var username string
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.auth.Me(ctx, &emptypb.Empty{})
 		if err != nil {
 			return err
@@ -42,8 +126,56 @@ func (c *Client) Me() (string, error) {
 	return username, err
 }
 
-func (c *Client) CreateAdmin(username, password string) error {
-	return c.call(func(ctx context.Context) error {
+// IsTokenValid checks the current token against Me without the re-login
+// that regular calls do, reporting false when the server rejects it.
+func (c *Client) IsTokenValid(ctx context.Context) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	_, err := c.auth.Me(c.withAuth(ctx), &emptypb.Empty{})
+	if status.Code(err) == codes.Unauthenticated {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Ping checks that the server is reachable and accepts the current token,
+// without logging in again. It fails with ErrUnavailable when the server
+// can't be reached in time, and with ErrUnauthenticated when the token is
+// rejected.
+func (c *Client) Ping(ctx context.Context) error {
+	ok, err := c.IsTokenValid(ctx)
+	switch {
+	case err != nil:
+		if code := status.Code(err); ctx.Err() == nil && (code == codes.Unavailable || code == codes.DeadlineExceeded) {
+			return fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		return err
+	case !ok:
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+// Logout drops the token used for ctx, so the next call logs in again. The
+// gRPC API has no logout call, so the server still accepts the dropped
+// token until it expires; the REST client's Logout revokes it.
+func (c *Client) Logout(ctx context.Context) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	c.setSession(ctx, "")
+	return nil
+}
+
+func (c *Client) CreateAdmin(ctx context.Context, username, password string) error {
+	return c.call(ctx, func(ctx context.Context) error {
 		_, err := c.auth.CreateAdmin(ctx, &pb.CreateAdminRequest{
 			Username: username,
 			Password: password,
@@ -51,3 +183,9 @@ func (c *Client) CreateAdmin(username, password string) error {
 		return err
 	})
 }
+
+// ScopedClient would return a client acting as the given sub-admin. The Wyron
+// API exposes no token exchange, so it always fails with ErrUnsupported.
+func (c *Client) ScopedClient(subAdminUsername string) (*Client, error) {
+	return nil, fmt.Errorf("%w: token exchange for %q", ErrUnsupported, subAdminUsername)
+}
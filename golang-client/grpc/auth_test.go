@@ -0,0 +1,32 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+func TestLogout(t *testing.T) {
+	b := &fakeBackend{listServers: func() (*pb.ListServersResponse, error) {
+		return &pb.ListServersResponse{}, nil
+	}}
+	c := b.client(t)
+	ctx := context.Background()
+
+	if _, err := c.ListServers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.logins.Load(); got != 1 {
+		t.Fatalf("logins after Logout = %d, want 1: it must not log in itself", got)
+	}
+	if _, err := c.ListServers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.logins.Load(); got != 2 {
+		t.Errorf("logins = %d, want 2: the call after Logout logs in again", got)
+	}
+}
@@ -0,0 +1,212 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var errUnauthenticated = status.Error(codes.Unauthenticated, "invalid credentials")
+
+// fakeBackend serves the Wyron services on a loopback port. Login accepts
+// the credentials "u"/"p" and every other RPC needs the token it issued
+// last; they use the handlers set before start and answer Unimplemented
+// without one.
+type fakeBackend struct {
+	addr   string
+	logins atomic.Int32
+
+	mu    sync.Mutex
+	token string
+	mds   []metadata.MD
+
+	listUsers       func(*pb.ListUsersRequest) (*pb.ListUsersResponse, error)
+	getUser         func(*pb.UserKeyRequest) (*pb.User, error)
+	editUser        func(*pb.EditUserRequest) (*pb.User, error)
+	listServers     func() (*pb.ListServersResponse, error)
+	getServer       func(*pb.ServerIDRequest) (*pb.Server, error)
+	updateInterface func(*pb.InterfaceRequest) (*pb.UpdateInterfaceResponse, error)
+}
+
+func (b *fakeBackend) start(t *testing.T) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(b.checkAuth))
+	pb.RegisterAuthServiceServer(s, fakeAuth{b: b})
+	pb.RegisterUserServiceServer(s, fakeUsers{b: b})
+	pb.RegisterServerServiceServer(s, fakeServers{b: b})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	b.addr = lis.Addr().String()
+}
+
+// client starts b and returns a client logged in to it.
+func (b *fakeBackend) client(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+	b.start(t)
+	c, err := New(b.addr, append([]Option{WithLogin("u", "p")}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// expireToken invalidates the issued token, so the next call is rejected
+// until the client logs in again.
+func (b *fakeBackend) expireToken() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.token = ""
+}
+
+// incoming returns the metadata of every call so far, logins
+// included.
+func (b *fakeBackend) incoming() []metadata.MD {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.mds)
+}
+
+func (b *fakeBackend) checkAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	b.mu.Lock()
+	b.mds = append(b.mds, md)
+	b.mu.Unlock()
+	if info.FullMethod == pb.AuthService_Login_FullMethodName {
+		return handler(ctx, req)
+	}
+	b.mu.Lock()
+	ok := b.token != "" && slices.Contains(md.Get("authorization"), "Bearer "+b.token)
+	b.mu.Unlock()
+	if !ok {
+		return nil, errUnauthenticated
+	}
+	return handler(ctx, req)
+}
+
+type fakeAuth struct {
+	pb.UnimplementedAuthServiceServer
+	b *fakeBackend
+}
+
+func (a fakeAuth) Login(_ context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if req.GetUsername() != "u" || req.GetPassword() != "p" {
+		return nil, errUnauthenticated
+	}
+	tok := fmt.Sprintf("tok-%d", a.b.logins.Add(1))
+	a.b.mu.Lock()
+	a.b.token = tok
+	a.b.mu.Unlock()
+	return &pb.LoginResponse{Token: tok}, nil
+}
+
+func (a fakeAuth) Me(context.Context, *emptypb.Empty) (*pb.MeResponse, error) {
+	return &pb.MeResponse{Username: "u"}, nil
+}
+
+type fakeUsers struct {
+	pb.UnimplementedUserServiceServer
+	b *fakeBackend
+}
+
+func (u fakeUsers) List(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	if u.b.listUsers == nil {
+		return u.UnimplementedUserServiceServer.List(ctx, req)
+	}
+	return u.b.listUsers(req)
+}
+
+func (u fakeUsers) Get(ctx context.Context, req *pb.UserKeyRequest) (*pb.User, error) {
+	if u.b.getUser == nil {
+		return u.UnimplementedUserServiceServer.Get(ctx, req)
+	}
+	return u.b.getUser(req)
+}
+
+func (u fakeUsers) Edit(ctx context.Context, req *pb.EditUserRequest) (*pb.User, error) {
+	if u.b.editUser == nil {
+		return u.UnimplementedUserServiceServer.Edit(ctx, req)
+	}
+	return u.b.editUser(req)
+}
+
+type fakeServers struct {
+	pb.UnimplementedServerServiceServer
+	b *fakeBackend
+}
+
+func (s fakeServers) List(ctx context.Context, req *emptypb.Empty) (*pb.ListServersResponse, error) {
+	if s.b.listServers == nil {
+		return s.UnimplementedServerServiceServer.List(ctx, req)
+	}
+	return s.b.listServers()
+}
+
+func (s fakeServers) Get(ctx context.Context, req *pb.ServerIDRequest) (*pb.Server, error) {
+	if s.b.getServer == nil {
+		return s.UnimplementedServerServiceServer.Get(ctx, req)
+	}
+	return s.b.getServer(req)
+}
+
+func (s fakeServers) UpdateInterface(ctx context.Context, req *pb.InterfaceRequest) (*pb.UpdateInterfaceResponse, error) {
+	if s.b.updateInterface == nil {
+		return s.UnimplementedServerServiceServer.UpdateInterface(ctx, req)
+	}
+	return s.b.updateInterface(req)
+}
+
+// usersPages serves users in pages of at most size, whatever limit is
+// asked for, as a server capping the page size would.
+func usersPages(users []*pb.User, size int) func(*pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	return func(req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+		skip := min(int(req.GetSkip()), len(users))
+		end := min(skip+min(int(req.GetLimit()), size), len(users))
+		return &pb.ListUsersResponse{Users: users[skip:end], Count: int64(len(users))}, nil
+	}
+}
+
+func TestConcurrentUnauthenticatedLogsInOnce(t *testing.T) {
+	const n = 20
+
+	b := &fakeBackend{getUser: func(req *pb.UserKeyRequest) (*pb.User, error) {
+		return &pb.User{UserKey: req.GetUserKey()}, nil
+	}}
+	c := b.client(t)
+	b.expireToken()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for range n {
+		wg.Go(func() {
+			if _, err := c.GetUser(context.Background(), "k"); err != nil {
+				errs <- err
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// one initial login plus exactly one re-login
+	if got := b.logins.Load(); got != 2 {
+		t.Fatalf("logins = %d, want 2", got)
+	}
+}
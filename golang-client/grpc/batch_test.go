@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+func TestUpdateInterfacesBatchMerges(t *testing.T) {
+	var mu sync.Mutex
+	var sent []*pb.InterfaceRequest
+	b := &fakeBackend{
+		getServer: func(req *pb.ServerIDRequest) (*pb.Server, error) {
+			return &pb.Server{Id: req.GetId(), Interfaces: []*pb.WireGuardInterface{
+				{Name: "wg0", DisplayName: "Main", Endpoint: "vpn.example.com", Dns: "1.1.1.1"},
+			}}, nil
+		},
+		updateInterface: func(req *pb.InterfaceRequest) (*pb.UpdateInterfaceResponse, error) {
+			mu.Lock()
+			sent = append(sent, req)
+			mu.Unlock()
+			return &pb.UpdateInterfaceResponse{Interface: &pb.WireGuardInterface{Name: req.GetName()}}, nil
+		},
+	}
+	c := b.client(t)
+
+	dns := "9.9.9.9"
+	errs, err := c.UpdateInterfacesBatch(context.Background(), []InterfaceUpdate{
+		{ServerID: "srv", Interface: "wg0", DNS: &dns},
+		{ServerID: "srv", Interface: "wg9", DNS: &dns},
+	}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := errs["srv/wg0"]; err != nil {
+		t.Errorf("srv/wg0: %v", err)
+	}
+	if err := errs["srv/wg9"]; !errors.Is(err, ErrInterfaceNotFound) {
+		t.Errorf("srv/wg9: err = %v, want ErrInterfaceNotFound", err)
+	}
+
+	if len(sent) != 1 {
+		t.Fatalf("sent %d updates, want 1", len(sent))
+	}
+	got := sent[0]
+	if got.GetServerId() != "srv" || got.GetName() != "wg0" || got.GetDns() != dns ||
+		got.GetDisplayName() != "Main" || got.GetEndpoint() != "vpn.example.com" {
+		t.Errorf("sent %v, want the DNS changed and the other fields kept", got)
+	}
+}
+
+func TestUpdateInterfacesBatchRejectsDuplicates(t *testing.T) {
+	c := (&fakeBackend{}).client(t)
+	_, err := c.UpdateInterfacesBatch(context.Background(), []InterfaceUpdate{
+		{ServerID: "srv", Interface: "wg0"},
+		{ServerID: "srv", Interface: "wg0"},
+	}, 1)
+	if err == nil {
+		t.Fatal("no error for a duplicate interface")
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	b := &fakeBackend{listServers: func() (*pb.ListServersResponse, error) {
+		return &pb.ListServersResponse{Servers: []*pb.Server{
+			{Id: "srv", Interfaces: []*pb.WireGuardInterface{{Name: "wg0"}}},
+		}}, nil
+	}}
+	c := b.client(t)
+
+	access := func(server, iface string) []AccessRequest {
+		return []AccessRequest{{ServerID: server, Interfaces: []string{iface}}}
+	}
+	params := []CreateUserParams{
+		{UserKey: "a", SocialID: 1, ServerAccess: access("srv", "wg0")},
+		{UserKey: "a", SocialID: 2},
+		{UserKey: "c", SocialID: 1},
+		{UserKey: "d", SocialID: 4, ServerAccess: access("gone", "wg0")},
+		{UserKey: "e", SocialID: 5, ServerAccess: access("srv", "wg9")},
+		{UserKey: "f", SocialID: 6},
+	}
+	// the field each entry fails on, "" for entries that pass
+	want := []string{"", FieldUserKey, FieldSocialID, FieldServerAccess, FieldServerAccess, ""}
+
+	errs, err := c.ValidateBatch(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != len(params) {
+		t.Fatalf("got %d results for %d entries", len(errs), len(params))
+	}
+	for n, field := range want {
+		if field == "" {
+			if errs[n] != nil {
+				t.Errorf("entry %d: unexpected %v", n, errs[n])
+			}
+			continue
+		}
+		var ve *ValidationError
+		if !errors.As(errs[n], &ve) || len(ve.Errors) != 1 || ve.Errors[0].Field != field {
+			t.Errorf("entry %d: err = %v, want one error on %s", n, errs[n], field)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package grpc
+
+import "context"
+
+// Bundle is what a new user is handed to get connected: the user, their
+// subscription link and one labeled config per peer.
+type Bundle struct {
+	User            *User
+	SubscriptionURL string
+	Configs         []GeneratedConfig
+}
+
+// ProvisioningBundle fetches the user and assembles their Bundle, resolving
+// each interface once. SubscriptionURL stays empty unless
+// Config.SubscriptionBaseURL is set. Peers that fail to render are left out
+// and reported in the returned error alongside the partial bundle.
+func (c *Client) ProvisioningBundle(ctx context.Context, userKey string, opts ConfigOptions) (*Bundle, error) {
+	user, err := c.GetUser(ctx, userKey)
+	if err != nil {
+		return nil, err
+	}
+	confs, err := c.GenerateUserConfigs(ctx, user, opts)
+	return &Bundle{
+		User:            user,
+		SubscriptionURL: c.subscriptionURL(user.SubToken),
+		Configs:         confs,
+	}, err
+}
@@ -3,18 +3,32 @@ package grpc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
 	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"github.com/wyronapp/wyron-public/golang-client/internal/creds"
+	"github.com/wyronapp/wyron-public/golang-client/internal/dialer"
+	"github.com/wyronapp/wyron-public/golang-client/internal/op"
+	"github.com/wyronapp/wyron-public/golang-client/internal/ratelimit"
+	"github.com/wyronapp/wyron-public/golang-client/internal/relogin"
+	"github.com/wyronapp/wyron-public/golang-client/internal/retry"
+	"github.com/wyronapp/wyron-public/golang-client/internal/swr"
+	"github.com/wyronapp/wyron-public/golang-client/internal/tlsconf"
+	"github.com/wyronapp/wyron-public/golang-client/internal/token"
+	"github.com/wyronapp/wyron-public/golang-client/internal/useragent"
 	"golang.org/x/net/proxy"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 	grpcmd "google.golang.org/grpc/metadata"
+	grpcstats "google.golang.org/grpc/stats"
 	"google.golang.org/grpc/status"
 )
 
@@ -24,24 +38,162 @@ type Config struct {
 	Password string
 	ProxyURL string
 
+	// AuthHost, ServerHost and UserHost route a single service to its own
+	// target, for gateways that split services across backends. Empty ones
+	// use Host, and services sharing a target share one connection.
+	AuthHost   string
+	ServerHost string
+	UserHost   string
+
 	Timeout time.Duration
 	Secure  bool
 	TLS     *credentials.TransportCredentials
+
+	// MinTLSVersion and CipherSuites restrict the TLS handshake, e.g. to
+	// tls.VersionTLS13 only. The version must be at least TLS 1.2, and cipher
+	// suites only apply up to TLS 1.2 as Go doesn't make TLS 1.3 ones
+	// configurable. They apply when Secure is set without custom TLS.
+	MinTLSVersion uint16
+	CipherSuites  []uint16
+
+	// AuthHeaderName and AuthScheme shape the token metadata, by default
+	// "authorization: Bearer <token>". With a custom header name and no
+	// scheme the bare token is sent.
+	AuthHeaderName string
+	AuthScheme     string
+
+	// UserAgent identifies the client in server logs, by default
+	// "wyron-go-client/<version>"; grpc-go appends its own version.
+	UserAgent string
+
+	// DialTimeout bounds each connection attempt independently of Timeout,
+	// which bounds whole calls. Zero keeps grpc-go's connect timeout.
+	DialTimeout time.Duration
+
+	// Resolver replaces the system resolver for the backend connection, and
+	// StaticHosts pins hosts to fixed IPs so they are never looked up at all.
+	Resolver    *net.Resolver
+	StaticHosts map[string]string
+
+	// WaitForReady makes calls, login included, queue while the connection is
+	// connecting or reconnecting instead of failing fast with Unavailable.
+	// A call then only fails at its deadline, so an unreachable server costs
+	// a full Timeout per call; leave it off for latency-critical callers.
+	WaitForReady bool
+
+	// Keepalive pings idle connections so load balancers don't drop them
+	// silently. Nil sends no pings, as before. grpc-go raises Time to at
+	// least 10s, and servers reject pings more frequent than their
+	// enforcement policy allows (every 5 minutes by default) by closing the
+	// connection, so match it to the server.
+	Keepalive *keepalive.ClientParameters
+
+	// UnaryInterceptors wrap every call, login included, in order with the
+	// first outermost. They run inside the rate limit and see the auth
+	// metadata already set; a call retried, after a re-login or by Retry,
+	// passes through them again. The services have no streaming calls.
+	UnaryInterceptors []grpc.UnaryClientInterceptor
+
+	// StatsHandlers observe every RPC on the connections, e.g. the tracing
+	// handler of the otelwyron package.
+	StatsHandlers []grpcstats.Handler
+
+	// MaxLoginRetries retries the initial login in NewClient while the
+	// backend is unavailable, waiting LoginRetryBackoff (500ms by default)
+	// and doubling it up to 10s between attempts. Rejected credentials fail
+	// at once.
+	MaxLoginRetries   int
+	LoginRetryBackoff time.Duration
+
+	// ReloginDelay is waited after a re-login triggered by a rejected token,
+	// before the request is retried. At most MaxRelogins (5 by default) such
+	// re-logins may happen per ReloginWindow (a minute by default); beyond
+	// that requests fail with ErrAuthLoop instead of hammering the server.
+	ReloginDelay  time.Duration
+	MaxRelogins   int
+	ReloginWindow time.Duration
+
+	// FatalAuthCodes lists the reasons an Unauthenticated status can carry
+	// that a re-login won't fix, such as "account_disabled"; such a call fails
+	// with ErrAccountDisabled instead. gRPC has no response body, so the
+	// status message is compared to them case-insensitively. Without any
+	// codes every Unauthenticated call triggers a re-login.
+	FatalAuthCodes []string
+
+	// SkipBatchPreflight stops the *Batch methods from calling Preflight
+	// before their first call.
+	SkipBatchPreflight bool
+
+	// RefreshAhead re-logins before a call once the token expires within this
+	// window. Zero keeps the reactive re-login on Unauthenticated only.
+	RefreshAhead time.Duration
+
+	// ReadCacheFresh and ReadCacheStale enable a stale-while-revalidate cache
+	// for ListServers and GetServer: results are reused for ReadCacheFresh,
+	// then served stale for up to ReadCacheStale more while a background
+	// refresh runs. Server writes through this client purge the cache; changes
+	// made elsewhere show up after at most ReadCacheFresh+ReadCacheStale.
+	// Entries are kept per credential set (see WithCredentials), so tenants
+	// never see each other's results. Cached values are shared between
+	// callers and must not be modified.
+	ReadCacheFresh time.Duration
+	ReadCacheStale time.Duration
+
+	// RateLimit caps outgoing calls to this many per second across all
+	// goroutines, allowing bursts of up to RateBurst (1 by default). Logins,
+	// re-login retries and batch calls all count, and waiting honors the
+	// call's context. Zero leaves calls unlimited.
+	RateLimit float64
+	RateBurst int
+
+	// RequestLogger, when set, is called after every RPC, logins included,
+	// with its full method name, status code and duration, failures
+	// included. A re-login or retry shows up as separate RPCs. Nothing
+	// carrying credentials or tokens is passed to it.
+	RequestLogger func(method string, code codes.Code, took time.Duration)
+
+	// MetricsObserver, when set, is called once per client method call with
+	// the operation, e.g. "users.List", its duration and its final error,
+	// after any re-login and retries.
+	MetricsObserver func(op string, took time.Duration, err error)
+
+	// Retry retries calls failing with Unavailable, or DeadlineExceeded
+	// reported by the server, within the call's deadline. Only reads and
+	// the updates and deletes that can be safely repeated are retried
+	// unless Retry.NonIdempotent is set, as a create may have taken effect
+	// before failing. The zero policy never retries.
+	Retry RetryPolicy
+
+	// SubscriptionBaseURL is the base of the REST API, e.g.
+	// "https://panel.example.com/api", that subscription links point to. The
+	// gRPC connection can't serve them, so links are only built when set.
+	SubscriptionBaseURL string
 }
 
 type Client struct {
 	cfg Config
 
-	conn *grpc.ClientConn
+	conns []*grpc.ClientConn
 
 	auth   pb.AuthServiceClient
 	server pb.ServerServiceClient
 	user   pb.UserServiceClient
 
-	mu    sync.RWMutex
-	token string
+	mu       sync.RWMutex
+	token    string
+	tokenExp time.Time
+
+	// tenants holds the tokens of credentials set through WithCredentials.
+	tenants creds.Store
 
 	loginMu sync.Mutex
+
+	stats stats
+
+	relogins *relogin.Limiter
+
+	serverList *swr.Cache[[]*Server]
+	serverByID *swr.Cache[*Server]
 }
 
 func NewClient(cfg Config) (*Client, error) {
@@ -51,53 +203,156 @@ func NewClient(cfg Config) (*Client, error) {
 	if cfg.Timeout <= 0 {
 		cfg.Timeout = 15 * time.Second
 	}
+	if cfg.MaxRelogins <= 0 {
+		cfg.MaxRelogins = 5
+	}
+	if cfg.ReloginWindow <= 0 {
+		cfg.ReloginWindow = time.Minute
+	}
+	if cfg.LoginRetryBackoff <= 0 {
+		cfg.LoginRetryBackoff = 500 * time.Millisecond
+	}
+	if err := dialer.ValidateHosts(cfg.StaticHosts); err != nil {
+		return nil, err
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = useragent.Default()
+	}
+	var err error
+	if cfg.AuthHeaderName, cfg.AuthScheme, err = token.Header(cfg.AuthHeaderName, cfg.AuthScheme); err != nil {
+		return nil, err
+	}
+	// metadata keys are lowercase on the wire
+	cfg.AuthHeaderName = strings.ToLower(cfg.AuthHeaderName)
+
+	tlsCfg, err := tlsconf.Build(cfg.MinTLSVersion, cfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil && (!cfg.Secure || cfg.TLS != nil) {
+		return nil, errors.New("MinTLSVersion/CipherSuites need Secure without custom TLS")
+	}
 
 	var opts []grpc.DialOption
 	if cfg.Secure {
 		if cfg.TLS != nil {
 			opts = append(opts, grpc.WithTransportCredentials(*cfg.TLS))
 		} else {
-			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(nil)))
+			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsCfg)))
 		}
 	} else {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
+	d := &dialer.Dialer{
+		Dialer: &net.Dialer{
+			Timeout:  cfg.DialTimeout,
+			Resolver: cfg.Resolver,
+		},
+		Hosts: cfg.StaticHosts,
+	}
 	if cfg.ProxyURL != "" {
 		proxyURL, err := url.Parse(cfg.ProxyURL)
 		if err != nil {
 			return nil, err
 		}
 
-		dialer, err := proxy.FromURL(proxyURL, proxy.Direct)
+		pd, err := proxy.FromURL(proxyURL, d)
 		if err != nil {
 			return nil, err
 		}
 
 		opts = append(opts, grpc.WithContextDialer(
 			func(ctx context.Context, addr string) (net.Conn, error) {
-				return dialer.Dial("tcp", addr)
+				return pd.Dial("tcp", d.Rewrite(addr))
+			},
+		))
+	} else if cfg.DialTimeout > 0 || cfg.Resolver != nil || len(cfg.StaticHosts) > 0 {
+		opts = append(opts, grpc.WithContextDialer(
+			func(ctx context.Context, addr string) (net.Conn, error) {
+				return d.DialContext(ctx, "tcp", addr)
 			},
 		))
 	}
 
-	conn, err := grpc.NewClient(cfg.Host, opts...)
-	if err != nil {
-		return nil, err
+	opts = append(opts, grpc.WithUserAgent(cfg.UserAgent))
+	if cfg.WaitForReady {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+	}
+	if cfg.Keepalive != nil {
+		opts = append(opts, grpc.WithKeepaliveParams(*cfg.Keepalive))
+	}
+	if cfg.MetricsObserver != nil {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(opInterceptor))
+	}
+	if cfg.Retry.Enabled() {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(retryInterceptor(cfg.Retry)))
+	}
+	if cfg.RequestLogger != nil {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(logInterceptor(cfg.RequestLogger)))
+	}
+	if l := ratelimit.New(cfg.RateLimit, cfg.RateBurst); l != nil {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(limitInterceptor(l)))
+	}
+	if len(cfg.UnaryInterceptors) > 0 {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(cfg.UnaryInterceptors...))
+	}
+	for _, h := range cfg.StatsHandlers {
+		opts = append(opts, grpc.WithStatsHandler(h))
 	}
 
 	c := &Client{
-		cfg:  cfg,
-		conn: conn,
+		cfg: cfg,
+
+		relogins: relogin.NewLimiter(cfg.MaxRelogins, cfg.ReloginWindow),
+
+		serverList: swr.New[[]*Server](cfg.ReadCacheFresh, cfg.ReadCacheStale),
+		serverByID: swr.New[*Server](cfg.ReadCacheFresh, cfg.ReadCacheStale),
+	}
+
+	byTarget := make(map[string]*grpc.ClientConn)
+	dial := func(host string) (*grpc.ClientConn, error) {
+		if host == "" {
+			host = cfg.Host
+		}
+		target := host
+		// grpc's own dns resolver would look the host up before our dialer sees it
+		if (cfg.Resolver != nil || len(cfg.StaticHosts) > 0) && !strings.Contains(target, ":///") {
+			target = "passthrough:///" + target
+		}
+		if conn, ok := byTarget[target]; ok {
+			return conn, nil
+		}
+		conn, err := grpc.NewClient(target, opts...)
+		if err != nil {
+			return nil, err
+		}
+		byTarget[target] = conn
+		c.conns = append(c.conns, conn)
+		return conn, nil
+	}
 
-		auth:   pb.NewAuthServiceClient(conn),
-		server: pb.NewServerServiceClient(conn),
-		user:   pb.NewUserServiceClient(conn),
+	authConn, err := dial(cfg.AuthHost)
+	if err != nil {
+		return nil, err
 	}
+	serverConn, err := dial(cfg.ServerHost)
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	userConn, err := dial(cfg.UserHost)
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	c.auth = pb.NewAuthServiceClient(authConn)
+	c.server = pb.NewServerServiceClient(serverConn)
+	c.user = pb.NewUserServiceClient(userConn)
 
 	// initial login
-	if err := c.Login(context.Background()); err != nil {
-		_ = conn.Close()
+	if err := c.initialLogin(context.Background()); err != nil {
+		_ = c.Close()
 		return nil, err
 	}
 
@@ -105,10 +360,13 @@ func NewClient(cfg Config) (*Client, error) {
 }
 
 func (c *Client) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
+	var errs []error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 func (c *Client) getToken() string {
@@ -120,34 +378,177 @@ func (c *Client) getToken() string {
 func (c *Client) setToken(tok string) {
 	c.mu.Lock()
 	c.token = tok
+	c.tokenExp = token.Expiry(tok)
 	c.mu.Unlock()
 }
 
+// TokenExpiry returns the exp claim of the current token, or the zero time
+// when the token is not a JWT.
+func (c *Client) TokenExpiry() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tokenExp
+}
+
+// ParseTokenClaims returns the claims of the current token, such as exp, sub
+// or roles. The signature is not verified, so the claims are only fit for
+// display and scheduling, not for trust decisions.
+func (c *Client) ParseTokenClaims() (map[string]any, error) {
+	claims, err := token.Claims(c.getToken())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenNotJWT, err)
+	}
+	return claims, nil
+}
+
+func limitInterceptor(l *ratelimit.Limiter) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := l.Wait(ctx); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func logInterceptor(log func(string, codes.Code, time.Duration)) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		log(method, status.Code(err), time.Since(start))
+		return err
+	}
+}
+
+// idempotentMethods can be repeated without changing the outcome.
+var idempotentMethods = map[string]bool{
+	pb.AuthService_Me_FullMethodName:                true,
+	pb.ServerService_List_FullMethodName:            true,
+	pb.ServerService_Get_FullMethodName:             true,
+	pb.ServerService_Update_FullMethodName:          true,
+	pb.ServerService_Delete_FullMethodName:          true,
+	pb.ServerService_UpdateInterface_FullMethodName: true,
+	pb.ServerService_DeleteInterface_FullMethodName: true,
+	pb.UserService_Get_FullMethodName:               true,
+	pb.UserService_List_FullMethodName:              true,
+	pb.UserService_Metrics_FullMethodName:           true,
+	pb.UserService_Edit_FullMethodName:              true,
+	pb.UserService_Delete_FullMethodName:            true,
+}
+
+func retryInterceptor(p RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !p.NonIdempotent && !idempotentMethods[method] {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		for n := 1; ; n++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			// a DeadlineExceeded of our own ctx is final
+			switch status.Code(err) {
+			case codes.Unavailable, codes.DeadlineExceeded:
+			default:
+				return err
+			}
+			d := p.Delay(n)
+			if n >= p.MaxAttempts || ctx.Err() != nil || !retry.Fits(ctx, d) {
+				return err
+			}
+			if werr := retry.Wait(ctx, d); werr != nil {
+				return err
+			}
+		}
+	}
+}
+
 func (c *Client) withAuth(ctx context.Context) context.Context {
-	tok := c.getToken()
+	tok, _ := c.session(ctx)
+	return c.withToken(ctx, tok)
+}
+
+// withToken is withAuth for a token read earlier.
+func (c *Client) withToken(ctx context.Context, tok string) context.Context {
 	if tok == "" {
 		return ctx
 	}
-	return grpcmd.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tok)
+	return grpcmd.AppendToOutgoingContext(ctx, c.cfg.AuthHeaderName, token.Value(c.cfg.AuthScheme, tok))
 }
 
-func (c *Client) call(fn func(ctx context.Context) error) error {
-	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
-	defer cancel()
+// call runs fn with an authenticated ctx, re-logging in once if the token is
+// rejected. A ctx without a deadline gets Config.Timeout.
+func (c *Client) call(ctx context.Context, fn func(ctx context.Context) error) error {
+	c.stats.inFlight.Add(1)
+	c.stats.totalCalls.Add(1)
+	defer c.stats.inFlight.Add(-1)
+
+	var method string
+	if c.cfg.MetricsObserver != nil {
+		ctx = context.WithValue(ctx, methodKey{}, &method)
+	}
+	start := time.Now()
+	err := c.invoke(ctx, fn)
+	if err != nil {
+		c.stats.totalErrors.Add(1)
+	}
+	if c.cfg.MetricsObserver != nil {
+		c.cfg.MetricsObserver(op.FromGRPC(method), time.Since(start), err)
+	}
+	return err
+}
+
+// methodKey carries where opInterceptor stores the method of a call's RPCs.
+type methodKey struct{}
+
+// opInterceptor records the RPC a call made for MetricsObserver. The last
+// one wins, as a login ahead of the call's own RPC comes first.
+func opInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if p, ok := ctx.Value(methodKey{}).(*string); ok {
+		*p = method
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (c *Client) invoke(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	if err := c.refreshIfExpiring(ctx); err != nil {
+		return err
+	}
 
 	// 1st attempt
-	err := fn(c.withAuth(ctx))
+	used, _ := c.session(ctx)
+	err := fn(c.withToken(ctx, used))
 	if err == nil {
 		return nil
 	}
 
 	// retry once if unauthenticated
 	if status.Code(err) == codes.Unauthenticated {
-		if lerr := c.Login(ctx); lerr != nil {
-			return lerr
+		if c.fatalAuth(err) {
+			return fmt.Errorf("%w: %w", ErrAccountDisabled, err)
+		}
+		if rerr := c.reloginAfter(ctx, used); rerr != nil {
+			if errors.Is(rerr, ErrAuthLoop) {
+				return fmt.Errorf("%w: %w", ErrAuthLoop, err)
+			}
+			return rerr
 		}
 		return fn(c.withAuth(ctx))
 	}
 
 	return err
 }
+
+// fatalAuth reports whether an Unauthenticated err carries one of
+// FatalAuthCodes.
+func (c *Client) fatalAuth(err error) bool {
+	msg := strings.TrimSpace(status.Convert(err).Message())
+	for _, code := range c.cfg.FatalAuthCodes {
+		if strings.EqualFold(msg, code) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// GeneratedConfig is one rendered peer config, labeled with its owner and
+// a FileName unique within the call that produced it. Label names the
+// server and interface for display, preferring their display names.
+type GeneratedConfig struct {
+	UserKey   string
+	SocialID  int64
+	ServerID  string
+	Interface string
+	FileName  string
+	Label     string
+	Config    string
+}
+
+// serverResolver memoizes GetServer and the interface lookup for the
+// duration of one bulk operation, failures included, so users sharing a
+// server cost a single fetch and peers sharing an interface a single scan.
+// It is safe for concurrent use; concurrent misses on the same key may both
+// fetch.
+type serverResolver struct {
+	c *Client
+
+	mu      sync.Mutex
+	servers map[string]resolved[*Server]
+	ifaces  map[ifaceKey]resolved[*WireGuardInterface]
+}
+
+type ifaceKey struct {
+	serverID string
+	name     string
+}
+
+type resolved[T any] struct {
+	val T
+	err error
+}
+
+func (c *Client) newServerResolver() *serverResolver {
+	return &serverResolver{
+		c:       c,
+		servers: make(map[string]resolved[*Server]),
+		ifaces:  make(map[ifaceKey]resolved[*WireGuardInterface]),
+	}
+}
+
+func (r *serverResolver) get(ctx context.Context, id string) (*Server, error) {
+	if id == "" {
+		return nil, ErrPeerNoServer
+	}
+	r.mu.Lock()
+	e, ok := r.servers[id]
+	r.mu.Unlock()
+	if ok {
+		return e.val, e.err
+	}
+
+	s, err := r.c.GetServer(ctx, id)
+	r.mu.Lock()
+	r.servers[id] = resolved[*Server]{s, err}
+	r.mu.Unlock()
+	return s, err
+}
+
+// iface resolves the interface p is on.
+func (r *serverResolver) iface(ctx context.Context, p *PeerState) (*WireGuardInterface, error) {
+	key := ifaceKey{p.ServerID, p.Interface}
+	r.mu.Lock()
+	e, ok := r.ifaces[key]
+	r.mu.Unlock()
+	if ok {
+		return e.val, e.err
+	}
+
+	srv, err := r.get(ctx, p.ServerID)
+	var iface *WireGuardInterface
+	if err == nil {
+		iface, err = p.resolveInterface(ctx, srv)
+	}
+	r.mu.Lock()
+	r.ifaces[key] = resolved[*WireGuardInterface]{iface, err}
+	r.mu.Unlock()
+	return iface, err
+}
+
+// GenerateUserConfigs renders every peer of user. Peers that fail are
+// skipped and reported in the returned error.
+func (c *Client) GenerateUserConfigs(ctx context.Context, user *User, opts ConfigOptions) ([]GeneratedConfig, error) {
+	return c.userConfigs(ctx, c.newServerResolver(), make(map[string]int), user, opts)
+}
+
+// GenerateConfigsForSocialID renders every peer of every user with the given
+// social ID. Users or peers that fail are skipped and reported in the
+// returned error.
+func (c *Client) GenerateConfigsForSocialID(ctx context.Context, socialID int64, opts ConfigOptions) ([]GeneratedConfig, error) {
+	var users []*User
+	it := c.UsersIterator(ListUsersOptions{SocialID: &socialID, Limit: maxPageSize, SortBy: SortByCreatedAt, OrderBy: OrderAsc})
+	for {
+		u, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		users = append(users, u)
+	}
+
+	r := c.newServerResolver()
+	used := make(map[string]int)
+	var out []GeneratedConfig
+	var errs []error
+	for _, u := range users {
+		confs, err := c.userConfigs(ctx, r, used, u, opts)
+		out = append(out, confs...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+func (c *Client) userConfigs(ctx context.Context, r *serverResolver, used map[string]int, user *User, opts ConfigOptions) ([]GeneratedConfig, error) {
+	if len(user.Peers) == 0 {
+		return nil, fmt.Errorf("user %s: %w", user.UserKey, ErrUserNoPeers)
+	}
+
+	var out []GeneratedConfig
+	var errs []error
+	for _, p := range user.Peers {
+		var conf string
+		iface, err := r.iface(ctx, p)
+		if err == nil {
+			conf, err = p.renderConfig(iface, opts)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s peer %s/%s: %w", user.UserKey, p.ServerID, p.Interface, err))
+			continue
+		}
+		srv, _ := r.get(ctx, p.ServerID)
+		out = append(out, GeneratedConfig{
+			UserKey:   user.UserKey,
+			SocialID:  user.SocialID,
+			ServerID:  p.ServerID,
+			Interface: p.Interface,
+			FileName:  archiveName(used, fmt.Sprintf("%d-%s", user.SocialID, p.ConfigFileName(user, srv))),
+			Label:     configLabel(srv, p.Interface),
+			Config:    conf,
+		})
+	}
+	return out, errors.Join(errs...)
+}
+
+func configLabel(srv *Server, ifaceName string) string {
+	server, iface := srv.Name, ifaceName
+	if srv.DisplayName != "" {
+		server = srv.DisplayName
+	}
+	for _, i := range srv.Interfaces {
+		if i.Name == ifaceName && i.DisplayName != "" {
+			iface = i.DisplayName
+			break
+		}
+	}
+	return server + " / " + iface
+}
@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"github.com/wyronapp/wyron-public/golang-client/rest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestConfigMatchesREST renders the same peer through both transports and
+// expects byte-identical configs.
+func TestConfigMatchesREST(t *testing.T) {
+	iface := rest.WireGuardInterface{
+		Name:      "wg0",
+		Endpoint:  "vpn.example.com",
+		DNS:       "1.1.1.1",
+		Port:      51820,
+		PublicKey: "c2VydmVyLXB1YmxpYy1rZXktYmFzZTY0LWVuY29kZWQ=",
+	}
+	tests := []struct {
+		name       string
+		addr       string
+		allowedIPs []string
+		opts       ConfigOptions
+	}{
+		{name: "defaults", addr: "10.0.0.2/32"},
+		{name: "dual stack", addr: "10.0.0.2/32, fd00::2/128"},
+		{name: "interface routes", addr: "10.0.0.2/32", allowedIPs: []string{"10.8.0.0/24"}},
+		{name: "unnormalized routes", addr: "10.0.0.2/32", allowedIPs: []string{" 10.8.0.0/24", "FD00::/8 "}},
+		{name: "options", addr: "10.0.0.2/32", opts: ConfigOptions{
+			OmitDNS:             true,
+			Table:               "off",
+			MTU:                 1420,
+			AllowedIPs:          []string{"192.168.0.0/16"},
+			PersistentKeepalive: 25 * time.Second,
+			PostUp:              []string{"iptables -A FORWARD -i %i -j ACCEPT"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ri := iface
+			ri.AllowedIPs = tt.allowedIPs
+			rp := rest.PeerState{ServerID: "srv", Interface: "wg0", AllowedAddress: tt.addr, PrivateKey: "cGVlci1wcml2YXRlLWtleS1iYXNlNjQtZW5jb2RlZD0="}
+			want, err := rp.GenerateConfigWithOptions(&rest.Server{Name: "srv", Interfaces: []rest.WireGuardInterface{ri}}, tt.opts)
+			if err != nil {
+				t.Fatalf("rest: %v", err)
+			}
+
+			gp := &PeerState{ServerID: rp.ServerID, Interface: rp.Interface, AllowedAddress: rp.AllowedAddress, PrivateKey: rp.PrivateKey}
+			gs := &Server{Name: "srv", Interfaces: []WireGuardInterface{{
+				Name:       ri.Name,
+				Endpoint:   ri.Endpoint,
+				DNS:        ri.DNS,
+				Port:       int32(ri.Port),
+				PublicKey:  ri.PublicKey,
+				AllowedIPs: ri.AllowedIPs,
+			}}}
+			got, err := gp.generateConfig(context.Background(), gs, tt.opts)
+			if err != nil {
+				t.Fatalf("grpc: %v", err)
+			}
+
+			if got != want {
+				t.Errorf("configs differ\ngrpc:\n%s\nrest:\n%s", got, want)
+			}
+		})
+	}
+}
+
+// TestConfigWithoutKeySkipsServer expects a peer without a private key to
+// fail with ErrInterfaceMissingKey before its server is fetched.
+func TestConfigWithoutKeySkipsServer(t *testing.T) {
+	var fetches atomic.Int32
+	b := &fakeBackend{
+		getUser: func(*pb.UserKeyRequest) (*pb.User, error) {
+			return &pb.User{UserKey: "k", Peers: []*pb.Peer{{ServerId: "srv", Interface: "wg0", AllowedAddress: "10.0.0.2/32"}}}, nil
+		},
+		getServer: func(*pb.ServerIDRequest) (*pb.Server, error) {
+			fetches.Add(1)
+			return nil, status.Error(codes.Unavailable, "down")
+		},
+	}
+	c := b.client(t)
+	ctx := context.Background()
+
+	u, err := c.GetUser(ctx, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := u.Peers[0]
+	if _, err := p.GenerateConfig(ctx); !errors.Is(err, ErrInterfaceMissingKey) {
+		t.Errorf("GenerateConfig: err = %v, want ErrInterfaceMissingKey", err)
+	}
+	if _, err := NormalizePeer(ctx, p); !errors.Is(err, ErrInterfaceMissingKey) {
+		t.Errorf("NormalizePeer: err = %v, want ErrInterfaceMissingKey", err)
+	}
+	if n := fetches.Load(); n != 0 {
+		t.Errorf("fetched the server %d times", n)
+	}
+}
@@ -0,0 +1,52 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+func TestEditUserIfUnchanged(t *testing.T) {
+	stored := &pb.User{UserKey: "k", SocialId: 7, TrafficLimit: 10 << 30}
+	limit := int64(20 << 30)
+	params := EditUserParams{TrafficLimit: &limit}
+
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  error
+	}{
+		{"unchanged", (&User{SocialID: 7, TrafficLimit: 10 << 30}).Version(), nil},
+		{"changed since read", (&User{SocialID: 7, TrafficLimit: 5 << 30}).Version(), ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var edits atomic.Int32
+			b := &fakeBackend{
+				getUser: func(*pb.UserKeyRequest) (*pb.User, error) { return stored, nil },
+				editUser: func(req *pb.EditUserRequest) (*pb.User, error) {
+					edits.Add(1)
+					return &pb.User{UserKey: req.GetUserKey(), SocialId: 7, TrafficLimit: req.GetTrafficLimit()}, nil
+				},
+			}
+			c := b.client(t)
+
+			u, err := c.EditUserIfUnchanged(context.Background(), "k", params, tt.expected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if n := edits.Load(); n != 0 {
+					t.Errorf("sent %d edits on a conflict", n)
+				}
+				return
+			}
+			if n := edits.Load(); n != 1 || u.TrafficLimit != uint64(limit) {
+				t.Errorf("sent %d edits, user = %+v; want one edit applied", n, u)
+			}
+		})
+	}
+}
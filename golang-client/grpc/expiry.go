@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"math"
+	"time"
+)
+
+// NoExpiry is returned by DaysUntilExpiry for users without a duration.
+const NoExpiry = math.MaxInt32
+
+// ExpiresAt returns when the user's duration runs out, counted from the first
+// connection. It is the zero time when the user never expires or has never
+// connected, since the duration only starts on first use.
+func (u *User) ExpiresAt() time.Time {
+	if u.DurationSeconds <= 0 || u.FirstConnectedAt <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(u.FirstConnectedAt, 0).Add(time.Duration(u.DurationSeconds) * time.Second)
+}
+
+// RenewalDate returns the local calendar day of ExpiresAt, or the zero time
+// under the same conditions.
+func (u *User) RenewalDate() time.Time {
+	exp := u.ExpiresAt()
+	if exp.IsZero() {
+		return exp
+	}
+	exp = exp.Local()
+	y, m, d := exp.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, exp.Location())
+}
+
+// DaysUntilExpiry returns the whole days left, rounded up, and a negative
+// count once expired. Never-connected users report their full duration and
+// users without a duration report NoExpiry.
+func (u *User) DaysUntilExpiry() int {
+	return u.daysUntilExpiry(now())
+}
+
+func (u *User) daysUntilExpiry(t time.Time) int {
+	if u.DurationSeconds <= 0 {
+		return NoExpiry
+	}
+	if u.FirstConnectedAt <= 0 {
+		return int(math.Ceil(float64(u.DurationSeconds) / 86400))
+	}
+
+	days := u.ExpiresAt().Sub(t).Hours() / 24
+	if days >= 0 {
+		return int(math.Ceil(days))
+	}
+	return int(math.Floor(days))
+}
+
+// IsExpired reports whether the user's duration has run out. Users that never
+// connected or have no duration are not expired.
+func (u *User) IsExpired() bool {
+	return u.isExpired(now())
+}
+
+func (u *User) isExpired(t time.Time) bool {
+	exp := u.ExpiresAt()
+	return !exp.IsZero() && !t.Before(exp)
+}
@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaysUntilExpiry(t *testing.T) {
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := int32(24 * time.Hour / time.Second)
+	tests := []struct {
+		name      string
+		duration  int32
+		firstConn int64
+		now       time.Time
+		want      int
+	}{
+		{"no duration", 0, first.Unix(), first, NoExpiry},
+		{"never connected", 30*day + 1, 0, first, 31},
+		{"partial day left", 30 * day, first.Unix(), first.Add(29*24*time.Hour + time.Hour), 1},
+		{"at expiry", 30 * day, first.Unix(), first.AddDate(0, 0, 30), 0},
+		{"expired", 30 * day, first.Unix(), first.AddDate(0, 0, 32).Add(time.Hour), -3},
+	}
+	defer SetClock(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetClock(func() time.Time { return tt.now })
+			u := &User{DurationSeconds: tt.duration, FirstConnectedAt: tt.firstConn}
+			if got := u.DaysUntilExpiry(); got != tt.want {
+				t.Errorf("DaysUntilExpiry() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummaryUsesClock(t *testing.T) {
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := &User{DurationSeconds: 10 * 86400, FirstConnectedAt: first.Unix()}
+
+	defer SetClock(nil)
+	SetClock(func() time.Time { return first.AddDate(0, 0, 4) })
+	if s := u.Summary(); s.DaysUntilExpiry != 6 || s.IsExpired {
+		t.Errorf("day 4: summary = %+v, want 6 days left", s)
+	}
+	SetClock(func() time.Time { return first.AddDate(0, 0, 10) })
+	if s := u.Summary(); s.DaysUntilExpiry != 0 || !s.IsExpired {
+		t.Errorf("day 10: summary = %+v, want expired", s)
+	}
+
+	SetClock(nil)
+	if !u.IsExpired() {
+		t.Error("SetClock(nil) did not restore the real clock")
+	}
+}
+
+func TestRenewalDate(t *testing.T) {
+	// a zone east of UTC, so a late-evening UTC expiry falls on the next
+	// local day
+	defer func(l *time.Location) { time.Local = l }(time.Local)
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+
+	first := time.Date(2026, 1, 1, 22, 0, 0, 0, time.UTC)
+	day := int32(24 * time.Hour / time.Second)
+	tests := []struct {
+		name      string
+		duration  int32
+		firstConn int64
+		want      time.Time
+	}{
+		{"never connected", 30 * day, 0, time.Time{}},
+		{"never expires", 0, first.Unix(), time.Time{}},
+		{"connected", 30 * day, first.Unix(), time.Date(2026, 2, 1, 0, 0, 0, 0, time.Local)},
+	}
+	defer SetClock(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &User{DurationSeconds: tt.duration, FirstConnectedAt: tt.firstConn}
+			// the date is fixed by the first connection, not by the clock
+			for _, at := range []time.Time{first, first.AddDate(0, 0, 45)} {
+				SetClock(func() time.Time { return at })
+				if got := u.RenewalDate(); !got.Equal(tt.want) {
+					t.Errorf("at %v: RenewalDate() = %v, want %v", at, got, tt.want)
+				}
+			}
+		})
+	}
+}
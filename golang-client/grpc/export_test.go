@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+// cancelAfter cancels once w has received n lines.
+type cancelAfter struct {
+	bytes.Buffer
+	n      int
+	cancel context.CancelFunc
+}
+
+func (w *cancelAfter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	if bytes.Count(w.Bytes(), []byte("\n")) >= w.n {
+		w.cancel()
+	}
+	return n, err
+}
+
+func TestExportUsersCSVResumes(t *testing.T) {
+	users := make([]*pb.User, 150)
+	for i := range users {
+		users[i] = &pb.User{UserKey: fmt.Sprintf("k%03d", i), SocialId: int64(i + 1)}
+	}
+	var mu sync.Mutex
+	var skips []int32
+	// the server caps pages at 60, so the first flush falls inside a page
+	list := usersPages(users, 60)
+	b := &fakeBackend{listUsers: func(req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+		mu.Lock()
+		skips = append(skips, req.GetSkip())
+		mu.Unlock()
+		return list(req)
+	}}
+	c := b.client(t)
+	opt := ListUsersOptions{Limit: maxPageSize, SortBy: SortByCreatedAt, OrderBy: OrderAsc}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := &cancelAfter{n: 1 + exportFlushRows, cancel: cancel}
+	cursor, err := c.ExportUsersCSV(ctx, first, opt, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if cursor != exportFlushRows {
+		t.Fatalf("cursor = %d, want %d", cursor, exportFlushRows)
+	}
+
+	var rest bytes.Buffer
+	cursor, err = c.ExportUsersCSV(context.Background(), &rest, opt, cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor != len(users) {
+		t.Errorf("cursor = %d, want %d", cursor, len(users))
+	}
+
+	rows, err := csv.NewReader(io.MultiReader(&first.Buffer, &rest)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1+len(users) || rows[0][0] != exportHeader[0] {
+		t.Fatalf("got %d rows, want the header and %d users", len(rows), len(users))
+	}
+	for i, u := range users {
+		if got := rows[1+i][0]; got != u.GetUserKey() {
+			t.Fatalf("row %d = %s, want %s", 1+i, got, u.GetUserKey())
+		}
+	}
+	if fmt.Sprint(skips) != "[0 60 100]" {
+		t.Errorf("skips = %v, want [0 60 100]", skips)
+	}
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryInterceptors(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			auth := "-"
+			if v := md.Get("authorization"); len(v) > 0 {
+				auth = v[0]
+			}
+			mu.Lock()
+			calls = append(calls, name+" "+method+" "+auth)
+			mu.Unlock()
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+	b := &fakeBackend{listServers: func() (*pb.ListServersResponse, error) {
+		return &pb.ListServersResponse{}, nil
+	}}
+	c := b.client(t, WithUnaryInterceptors(record("outer"), record("inner")))
+
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b.expireToken()
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	login, list := pb.AuthService_Login_FullMethodName, pb.ServerService_List_FullMethodName
+	want := []string{
+		"outer " + login + " -", "inner " + login + " -",
+		"outer " + list + " Bearer tok-1", "inner " + list + " Bearer tok-1",
+		// rejected, then retried after the re-login
+		"outer " + list + " Bearer tok-1", "inner " + list + " Bearer tok-1",
+		"outer " + login + " -", "inner " + login + " -",
+		"outer " + list + " Bearer tok-2", "inner " + list + " Bearer tok-2",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(calls, want) {
+		t.Errorf("calls:\n%q\nwant:\n%q", calls, want)
+	}
+}
@@ -0,0 +1,59 @@
+package grpc
+
+import "context"
+
+// UserIterator pages through ListUsers results, advancing skip by the page
+// size and stopping on an empty page or once the total count is reached. A
+// short page doesn't end it, as the server may cap the page size below
+// Limit.
+type UserIterator struct {
+	c   *Client
+	opt ListUsersOptions
+
+	page  []*User
+	pos   int
+	total int64
+	done  bool
+}
+
+func (c *Client) UsersIterator(opt ListUsersOptions) *UserIterator {
+	if opt.Limit == 0 {
+		opt.Limit = 50
+	}
+	return &UserIterator{c: c, opt: opt, total: -1}
+}
+
+// Next returns the next user, fetching a page when the current one is used
+// up. It reports false once all users have been returned.
+func (it *UserIterator) Next(ctx context.Context) (*User, bool, error) {
+	for it.pos >= len(it.page) {
+		if it.done {
+			return nil, false, nil
+		}
+		if err := it.fetch(ctx); err != nil {
+			return nil, false, err
+		}
+	}
+	u := it.page[it.pos]
+	it.pos++
+	return u, true, nil
+}
+
+// Total returns the number of matching users the server reported, once the
+// first page is fetched.
+func (it *UserIterator) Total() (int64, bool) {
+	return it.total, it.total >= 0
+}
+
+func (it *UserIterator) fetch(ctx context.Context) error {
+	page, count, err := it.c.ListUsers(ctx, it.opt)
+	if err != nil {
+		return err
+	}
+	it.page, it.pos, it.total = page, 0, count
+
+	it.opt.Skip += int32(len(page))
+	// a zero count with users on the page means the server didn't send one
+	it.done = len(page) == 0 || count > 0 && int64(it.opt.Skip) >= count
+	return nil
+}
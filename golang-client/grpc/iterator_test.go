@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func testUsers(n int) []*pb.User {
+	users := make([]*pb.User, n)
+	for i := range users {
+		users[i] = &pb.User{UserKey: fmt.Sprintf("u%d", i)}
+	}
+	return users
+}
+
+func drain(t *testing.T, it *UserIterator) []string {
+	t.Helper()
+	var keys []string
+	for {
+		u, ok, err := it.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return keys
+		}
+		keys = append(keys, u.UserKey)
+	}
+}
+
+func TestUsersIterator(t *testing.T) {
+	tests := []struct {
+		name      string
+		users     int
+		limit     int32
+		cap       int
+		wantPages int32
+	}{
+		{"empty", 0, 10, 10, 1},
+		{"exact pages", 20, 10, 10, 2},
+		{"last page short", 25, 10, 10, 3},
+		{"server caps page size", 25, 50, 10, 3},
+		{"default limit", 120, 0, 100, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pages atomic.Int32
+			serve := usersPages(testUsers(tt.users), tt.cap)
+			b := &fakeBackend{listUsers: func(req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+				pages.Add(1)
+				return serve(req)
+			}}
+			c := b.client(t)
+
+			it := c.UsersIterator(ListUsersOptions{Limit: tt.limit})
+			if _, ok := it.Total(); ok {
+				t.Error("Total known before the first page")
+			}
+			keys := drain(t, it)
+			if len(keys) != tt.users {
+				t.Fatalf("got %d users, want %d", len(keys), tt.users)
+			}
+			for i, k := range keys {
+				if want := fmt.Sprintf("u%d", i); k != want {
+					t.Fatalf("user %d = %s, want %s", i, k, want)
+				}
+			}
+			if total, ok := it.Total(); !ok || total != int64(tt.users) {
+				t.Errorf("Total() = %d, %v; want %d", total, ok, tt.users)
+			}
+			if got := pages.Load(); got != tt.wantPages {
+				t.Errorf("fetched %d pages, want %d", got, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestUsersIteratorWithoutCount(t *testing.T) {
+	users := testUsers(7)
+	b := &fakeBackend{listUsers: func(req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+		skip := min(int(req.GetSkip()), len(users))
+		end := min(skip+int(req.GetLimit()), len(users))
+		return &pb.ListUsersResponse{Users: users[skip:end]}, nil
+	}}
+	c := b.client(t)
+
+	if keys := drain(t, c.UsersIterator(ListUsersOptions{Limit: 3})); len(keys) != len(users) {
+		t.Errorf("got %d users, want %d", len(keys), len(users))
+	}
+}
+
+func TestUsersIteratorError(t *testing.T) {
+	b := &fakeBackend{listUsers: func(*pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+		return nil, status.Error(codes.Internal, "boom")
+	}}
+	c := b.client(t)
+
+	_, ok, err := c.UsersIterator(ListUsersOptions{}).Next(context.Background())
+	if ok || status.Code(err) != codes.Internal {
+		t.Fatalf("Next() = %v, %v; want the Internal error", ok, err)
+	}
+}
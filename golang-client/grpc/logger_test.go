@@ -0,0 +1,45 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"google.golang.org/grpc/codes"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var mu sync.Mutex
+	var lines []string
+	b := &fakeBackend{listServers: func() (*pb.ListServersResponse, error) {
+		return &pb.ListServersResponse{}, nil
+	}}
+	c := b.client(t, WithRequestLogger(func(method string, code codes.Code, took time.Duration) {
+		mu.Lock()
+		lines = append(lines, fmt.Sprintf("%s %v", method, code))
+		mu.Unlock()
+	}))
+
+	b.expireToken()
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	login, list := pb.AuthService_Login_FullMethodName, pb.ServerService_List_FullMethodName
+	want := []string{
+		login + " OK",
+		// the re-login shows up as separate RPCs
+		list + " Unauthenticated",
+		login + " OK",
+		list + " OK",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(lines, want) {
+		t.Errorf("logged %q, want %q", lines, want)
+	}
+}
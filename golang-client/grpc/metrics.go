@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"github.com/wyronapp/wyron-public/golang-client/internal/op"
+)
+
+// MetricValue fetches the metrics and returns the one named, by its wire
+// name such as "active_users".
+func (c *Client) MetricValue(ctx context.Context, name string) (float64, error) {
+	m, err := c.Metrics(ctx)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := metricValue(m, name)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownMetric, name)
+	}
+	return v, nil
+}
+
+func metricValue(m *pb.MetricsResponse, name string) (float64, bool) {
+	switch name {
+	case "total_users":
+		return float64(m.GetTotalUsers()), true
+	case "active_users":
+		return float64(m.GetActiveUsers()), true
+	case "disabled_users":
+		return float64(m.GetDisabledUsers()), true
+	case "expired_users":
+		return float64(m.GetExpiredUsers()), true
+	case "limited_users":
+		return float64(m.GetLimitedUsers()), true
+	case "total_usage":
+		return float64(m.GetTotalUsage()), true
+	case "total_traffic_limit":
+		return float64(m.GetTotalTrafficLimit()), true
+	}
+	return 0, false
+}
+
+// OperationName names an RPC by its full method the way MetricsObserver
+// does, e.g. "users.List" for "/user.UserService/List".
+func OperationName(fullMethod string) string {
+	return op.FromGRPC(fullMethod)
+}
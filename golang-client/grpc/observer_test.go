@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type observation struct {
+	op   string
+	code codes.Code
+}
+
+func TestMetricsObserver(t *testing.T) {
+	var got []observation
+	b := &fakeBackend{
+		listServers: func() (*pb.ListServersResponse, error) {
+			return &pb.ListServersResponse{}, nil
+		},
+		getServer: func(*pb.ServerIDRequest) (*pb.Server, error) {
+			return nil, status.Error(codes.NotFound, "no such server")
+		},
+	}
+	c := b.client(t, WithMetricsObserver(func(op string, took time.Duration, err error) {
+		got = append(got, observation{op, status.Code(err)})
+	}))
+	ctx := context.Background()
+
+	// one observation per call, after the re-login
+	b.expireToken()
+	if _, err := c.ListServers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetServer(ctx, "missing"); status.Code(err) != codes.NotFound {
+		t.Fatalf("err = %v, want NotFound", err)
+	}
+
+	want := []observation{{"servers.List", codes.OK}, {"servers.Get", codes.NotFound}}
+	if len(got) != len(want) {
+		t.Fatalf("observed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("observation %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
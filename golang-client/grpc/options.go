@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	grpcstats "google.golang.org/grpc/stats"
+)
+
+// Option sets Config fields for New. Options apply in order, so a later one
+// wins; fields without a dedicated option can be set by any func(*Config)
+// converted to an Option.
+type Option func(*Config)
+
+// New builds a client for host from options. WithLogin is required and New
+// fails with ErrMissingCredentials without it; every other setting keeps
+// its Config default.
+func New(host string, opts ...Option) (*Client, error) {
+	cfg := Config{Host: host}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if cfg.Username == "" || cfg.Password == "" {
+		return nil, ErrMissingCredentials
+	}
+	return NewClient(cfg)
+}
+
+// WithLogin sets the credentials the client logs in with. It is unrelated
+// to WithCredentials, which swaps them for a single context.
+func WithLogin(username, password string) Option {
+	return func(c *Config) {
+		c.Username, c.Password = username, password
+	}
+}
+
+// WithProxy dials the backend through a SOCKS5 proxy URL.
+func WithProxy(proxyURL string) Option {
+	return func(c *Config) { c.ProxyURL = proxyURL }
+}
+
+// WithTimeout bounds calls whose context has no deadline.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Config) { c.Timeout = d }
+}
+
+// WithSecure dials with TLS using the system roots.
+func WithSecure() Option {
+	return func(c *Config) { c.Secure = true }
+}
+
+// WithKeepalive pings idle connections with p; see Config.Keepalive.
+func WithKeepalive(p keepalive.ClientParameters) Option {
+	return func(c *Config) { c.Keepalive = &p }
+}
+
+// WithUnaryInterceptors appends to Config.UnaryInterceptors.
+func WithUnaryInterceptors(ics ...grpc.UnaryClientInterceptor) Option {
+	return func(c *Config) { c.UnaryInterceptors = append(c.UnaryInterceptors, ics...) }
+}
+
+// WithRateLimit caps outgoing calls to perSecond with bursts of up to
+// burst; see Config.RateLimit.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *Config) { c.RateLimit, c.RateBurst = perSecond, burst }
+}
+
+// WithUserAgent sets the User-Agent sent with every call.
+func WithUserAgent(ua string) Option {
+	return func(c *Config) { c.UserAgent = ua }
+}
+
+// WithRequestLogger sets Config.RequestLogger.
+func WithRequestLogger(log func(method string, code codes.Code, took time.Duration)) Option {
+	return func(c *Config) { c.RequestLogger = log }
+}
+
+// WithStatsHandlers appends to Config.StatsHandlers.
+func WithStatsHandlers(hs ...grpcstats.Handler) Option {
+	return func(c *Config) { c.StatsHandlers = append(c.StatsHandlers, hs...) }
+}
+
+// WithMetricsObserver sets Config.MetricsObserver.
+func WithMetricsObserver(obs func(op string, took time.Duration, err error)) Option {
+	return func(c *Config) { c.MetricsObserver = obs }
+}
@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"google.golang.org/grpc/keepalive"
+)
+
+func TestWithKeepalive(t *testing.T) {
+	p := keepalive.ClientParameters{Time: 30 * time.Second, Timeout: 5 * time.Second, PermitWithoutStream: true}
+	var cfg Config
+	WithKeepalive(p)(&cfg)
+	p.Time = time.Hour
+	if cfg.Keepalive == nil || cfg.Keepalive.Time != 30*time.Second || !cfg.Keepalive.PermitWithoutStream {
+		t.Fatalf("Keepalive = %+v, want a copy of the parameters", cfg.Keepalive)
+	}
+
+	b := &fakeBackend{listServers: func() (*pb.ListServersResponse, error) {
+		return &pb.ListServersResponse{}, nil
+	}}
+	c := b.client(t, WithKeepalive(*cfg.Keepalive))
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+// Field names used in FieldError, matching the wire names of the request.
+const (
+	FieldUserKey         = "user_key"
+	FieldTrafficLimit    = "traffic_limit"
+	FieldDurationSeconds = "duration_seconds"
+	FieldSocialID        = "social_id"
+	FieldServerAccess    = "server_access"
+)
+
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func validationError(errs []FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+type AccessRequest struct {
+	ServerID   string
+	Interfaces []string
+}
+
+type CreateUserParams struct {
+	UserKey         string
+	TrafficLimit    int64
+	DurationSeconds int64
+	SocialID        int64
+	ServerAccess    []AccessRequest
+}
+
+type EditUserParams struct {
+	TrafficLimit    *int64
+	DurationSeconds *int64
+	SocialID        *int64
+}
+
+func (p CreateUserParams) Validate() []FieldError {
+	var errs []FieldError
+	if p.TrafficLimit < 0 {
+		errs = append(errs, FieldError{FieldTrafficLimit, "must not be negative"})
+	}
+	if p.DurationSeconds < 0 {
+		errs = append(errs, FieldError{FieldDurationSeconds, "must not be negative"})
+	} else if p.DurationSeconds > math.MaxInt32 {
+		errs = append(errs, FieldError{FieldDurationSeconds, "too large"})
+	}
+	if p.SocialID <= 0 {
+		errs = append(errs, FieldError{FieldSocialID, "required"})
+	}
+	for i, a := range p.ServerAccess {
+		if a.ServerID == "" {
+			errs = append(errs, FieldError{FieldServerAccess, fmt.Sprintf("entry %d: server_id required", i)})
+		}
+		for _, name := range a.Interfaces {
+			if name == "" {
+				errs = append(errs, FieldError{FieldServerAccess, fmt.Sprintf("entry %d: empty interface name", i)})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+func (p EditUserParams) Validate() []FieldError {
+	var errs []FieldError
+	if p.TrafficLimit != nil && *p.TrafficLimit < 0 {
+		errs = append(errs, FieldError{FieldTrafficLimit, "must not be negative"})
+	}
+	if p.DurationSeconds != nil && *p.DurationSeconds < 0 {
+		errs = append(errs, FieldError{FieldDurationSeconds, "must not be negative"})
+	} else if p.DurationSeconds != nil && *p.DurationSeconds > math.MaxInt32 {
+		errs = append(errs, FieldError{FieldDurationSeconds, "too large"})
+	}
+	if p.SocialID != nil && *p.SocialID <= 0 {
+		errs = append(errs, FieldError{FieldSocialID, "must be positive"})
+	}
+	return errs
+}
+
+func (p CreateUserParams) toProto() *pb.CreateUserRequest {
+	req := &pb.CreateUserRequest{
+		UserKey:         p.UserKey,
+		TrafficLimit:    uint64(p.TrafficLimit),
+		DurationSeconds: int32(p.DurationSeconds),
+		SocialId:        p.SocialID,
+	}
+	for _, a := range p.ServerAccess {
+		req.ServerAccess = append(req.ServerAccess, &pb.AccessRequest{
+			ServerId:   a.ServerID,
+			Interfaces: a.Interfaces,
+		})
+	}
+	return req
+}
+
+func (p EditUserParams) toProto(userKey string) *pb.EditUserRequest {
+	req := &pb.EditUserRequest{
+		UserKey:  userKey,
+		SocialId: p.SocialID,
+	}
+	if p.TrafficLimit != nil {
+		v := uint64(*p.TrafficLimit)
+		req.TrafficLimit = &v
+	}
+	if p.DurationSeconds != nil {
+		v := int32(*p.DurationSeconds)
+		req.DurationSeconds = &v
+	}
+	return req
+}
+
+func withAccess(access []AccessRequest, serverID, iface string) []AccessRequest {
+	out := make([]AccessRequest, len(access))
+	copy(out, access)
+	for i, a := range out {
+		if a.ServerID != serverID {
+			continue
+		}
+		for _, name := range a.Interfaces {
+			if name == iface {
+				return out
+			}
+		}
+		out[i].Interfaces = append(append([]string{}, a.Interfaces...), iface)
+		return out
+	}
+	return append(out, AccessRequest{ServerID: serverID, Interfaces: []string{iface}})
+}
@@ -0,0 +1,82 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/probe"
+)
+
+const (
+	probeWait        = 2 * time.Second
+	probeConcurrency = 16
+)
+
+// ServerStatus is the probe result of one interface endpoint. Err is set
+// when the endpoint is missing or the probe of it failed.
+type ServerStatus struct {
+	ServerID  string
+	Interface string
+	Endpoint  string
+
+	// NotRefused is set when the endpoint resolved and a UDP datagram sent
+	// to it drew no refusal within the probe timeout. It rules out a
+	// misspelled host, a missing route and a closed port, but not a host
+	// that is down: WireGuard never answers unauthenticated packets.
+	NotRefused  bool
+	ResolveTime time.Duration
+	Err         error
+}
+
+// ProbeServers checks every interface endpoint of every server from the
+// client side, as the API reports no health; see ServerStatus.NotRefused
+// for what a passing probe does and doesn't show.
+func (c *Client) ProbeServers(ctx context.Context) ([]ServerStatus, error) {
+	servers, err := c.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return probeServers(ctx, servers, probeWait), nil
+}
+
+func probeServers(ctx context.Context, servers []*Server, wait time.Duration) []ServerStatus {
+	var out []ServerStatus
+	for _, s := range servers {
+		for _, i := range s.Interfaces {
+			st := ServerStatus{ServerID: s.Name, Interface: i.Name}
+			if i.Endpoint != "" && i.Port != 0 {
+				st.Endpoint = net.JoinHostPort(i.Endpoint, strconv.Itoa(int(i.Port)))
+			}
+			out = append(out, st)
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, probeConcurrency)
+	for n := range out {
+		st := &out[n]
+		if st.Endpoint == "" {
+			st.Err = fmt.Errorf("%w: endpoint missing", ErrInterfaceMissingKey)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				st.Err = ctx.Err()
+				return
+			}
+			st.ResolveTime, st.Err = probe.Check(ctx, st.Endpoint, wait)
+			st.NotRefused = st.Err == nil
+		}()
+	}
+	wg.Wait()
+	return out
+}
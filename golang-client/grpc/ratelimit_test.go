@@ -0,0 +1,33 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	b := &fakeBackend{listServers: func() (*pb.ListServersResponse, error) {
+		return &pb.ListServersResponse{}, nil
+	}}
+	// the login takes the one burst token
+	c := b.client(t, WithRateLimit(20, 1))
+
+	start := time.Now()
+	for range 3 {
+		if _, err := c.ListServers(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if took := time.Since(start); took < 120*time.Millisecond {
+		t.Errorf("3 calls at 20/s took %v, want at least 150ms", took)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.ListServers(ctx); err == nil {
+		t.Error("call succeeded although its deadline came before the next token")
+	}
+}
@@ -0,0 +1,11 @@
+package grpc
+
+// GroupServersByRegion groups servers by Region, keeping their order within
+// each group. Servers without a region are grouped under "".
+func GroupServersByRegion(servers []*Server) map[string][]*Server {
+	out := make(map[string][]*Server)
+	for _, s := range servers {
+		out[s.Region] = append(out[s.Region], s)
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		failures  []codes.Code
+		wantCode  codes.Code
+		wantCalls int32
+	}{
+		{"recovers", RetryPolicy{MaxAttempts: 3}, []codes.Code{codes.Unavailable, codes.DeadlineExceeded}, codes.OK, 3},
+		{"gives up", RetryPolicy{MaxAttempts: 2}, []codes.Code{codes.Unavailable, codes.Unavailable}, codes.Unavailable, 2},
+		{"disabled", RetryPolicy{}, []codes.Code{codes.Unavailable}, codes.Unavailable, 1},
+		{"not transient", RetryPolicy{MaxAttempts: 3}, []codes.Code{codes.Internal}, codes.Internal, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			b := &fakeBackend{listServers: func() (*pb.ListServersResponse, error) {
+				if n := int(calls.Add(1)); n <= len(tt.failures) {
+					return nil, status.Error(tt.failures[n-1], "fail")
+				}
+				return &pb.ListServersResponse{}, nil
+			}}
+			tt.policy.BaseDelay = time.Millisecond
+			c := b.client(t, func(c *Config) { c.Retry = tt.policy })
+
+			_, err := c.ListServers(context.Background())
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("err = %v, want %v", err, tt.wantCode)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("%d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	method := pb.UserService_Create_FullMethodName
+	for _, tt := range []struct {
+		nonIdempotent bool
+		want          int
+	}{{false, 1}, {true, 3}} {
+		calls := 0
+		invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			calls++
+			return status.Error(codes.Unavailable, "down")
+		}
+		ic := retryInterceptor(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, NonIdempotent: tt.nonIdempotent})
+		ic(context.Background(), method, nil, nil, nil, invoker)
+		if calls != tt.want {
+			t.Errorf("NonIdempotent %v: %d calls to Create, want %d", tt.nonIdempotent, calls, tt.want)
+		}
+	}
+}
+
+func TestRetryStopsAtDeadline(t *testing.T) {
+	calls := 0
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.Unavailable, "down")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ic := retryInterceptor(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second})
+	ic(ctx, pb.ServerService_List_FullMethodName, nil, nil, nil, invoker)
+	if calls != 1 {
+		t.Errorf("%d calls, want 1: the first wait passes the deadline", calls)
+	}
+}
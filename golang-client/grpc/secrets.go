@@ -0,0 +1,53 @@
+package grpc
+
+import "encoding/json"
+
+// MarshalJSON leaves out PrivateKey so users and peers can be logged or
+// passed on without leaking keys. Use User.WithSecrets or
+// PeerState.WithSecrets to serialize the key on purpose.
+func (p PeerState) MarshalJSON() ([]byte, error) {
+	p.PrivateKey = ""
+	return json.Marshal(secretPeer(p))
+}
+
+// WithSecrets returns p in a form that marshals including PrivateKey. A nil
+// p marshals as null.
+func (p *PeerState) WithSecrets() json.Marshaler {
+	if p == nil {
+		return json.RawMessage("null")
+	}
+	return secretPeer(*p)
+}
+
+// WithSecrets returns u in a form that marshals including the peers'
+// private keys. A nil u, like a nil peer, marshals as null.
+func (u *User) WithSecrets() json.Marshaler {
+	if u == nil {
+		return json.RawMessage("null")
+	}
+	return secretUser(*u)
+}
+
+type secretPeer PeerState
+
+func (p secretPeer) MarshalJSON() ([]byte, error) {
+	type plain PeerState
+	return json.Marshal(plain(p))
+}
+
+type secretUser User
+
+func (u secretUser) MarshalJSON() ([]byte, error) {
+	type plain User
+	var peers []*secretPeer
+	if u.Peers != nil {
+		peers = make([]*secretPeer, len(u.Peers))
+	}
+	for i, p := range u.Peers {
+		peers[i] = (*secretPeer)(p)
+	}
+	return json.Marshal(struct {
+		plain
+		Peers []*secretPeer
+	}{plain(u), peers})
+}
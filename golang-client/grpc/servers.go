@@ -2,69 +2,140 @@ package grpc
 
 import (
 	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
 
 	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"github.com/wyronapp/wyron-public/golang-client/internal/creds"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-func (c *Client) ListServers() ([]*Server, error) {
+func (c *Client) ListServers(ctx context.Context) ([]*Server, error) {
+	return c.serverList.Get(ctx, creds.Key(ctx), c.listServers)
+}
+
+func (c *Client) listServers(ctx context.Context) ([]*Server, error) {
 	var out []*Server
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.server.List(ctx, &emptypb.Empty{})
 		if err != nil {
 			return err
 		}
 		out = make([]*Server, 0, len(res.GetServers()))
 		for _, s := range res.GetServers() {
-			out = append(out, c.parseServer(s))
+			srv, err := c.parseServer(s)
+			if err != nil {
+				return err
+			}
+			out = append(out, srv)
 		}
 		return nil
 	})
 	return out, err
 }
 
-func (c *Client) GetServer(id string) (*Server, error) {
+func (c *Client) ListServersPublic(ctx context.Context) ([]PublicServer, error) {
+	servers, err := c.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PublicServer, 0, len(servers))
+	for _, s := range servers {
+		out = append(out, s.Public())
+	}
+	return out, nil
+}
+
+func (c *Client) ListAllInterfaces(ctx context.Context) ([]ServerInterface, error) {
+	servers, err := c.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []ServerInterface
+	for _, s := range servers {
+		for _, i := range s.Interfaces {
+			out = append(out, ServerInterface{ServerID: s.Name, Interface: i})
+		}
+	}
+	return out, nil
+}
+
+// DuplicateEndpoints groups interfaces by "endpoint:port" and keeps only the
+// groups shared by more than one interface. Interfaces without an endpoint
+// are ignored.
+func DuplicateEndpoints(ifaces []ServerInterface) map[string][]ServerInterface {
+	groups := make(map[string][]ServerInterface)
+	for _, si := range ifaces {
+		if si.Interface.Endpoint == "" {
+			continue
+		}
+		key := net.JoinHostPort(strings.ToLower(si.Interface.Endpoint), strconv.Itoa(int(si.Interface.Port)))
+		groups[key] = append(groups[key], si)
+	}
+	for key, g := range groups {
+		if len(g) < 2 {
+			delete(groups, key)
+		}
+	}
+	return groups
+}
+
+func (c *Client) GetServer(ctx context.Context, id string) (*Server, error) {
+	return c.serverByID.Get(ctx, creds.Key(ctx)+"/"+id, func(ctx context.Context) (*Server, error) {
+		return c.getServer(ctx, id)
+	})
+}
+
+func (c *Client) getServer(ctx context.Context, id string) (*Server, error) {
 	var out *Server
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.server.Get(ctx, &pb.ServerIDRequest{Id: id})
 		if err != nil {
 			return err
 		}
-		out = c.parseServer(res)
-		return nil
+		out, err = c.parseServer(res)
+		return err
 	})
 	return out, err
 }
 
-func (c *Client) CreateOrUpdateServer(req *pb.UpdateServerRequest) (*Server, error) {
+func (c *Client) CreateOrUpdateServer(ctx context.Context, req *pb.UpdateServerRequest) (*Server, error) {
 	var out *Server
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.server.Update(ctx, req)
 		if err != nil {
 			return err
 		}
-		out = c.parseServer(res)
-		return nil
+		out, err = c.parseServer(res)
+		return err
 	})
+	c.purgeServers()
 	return out, err
 }
 
-func (c *Client) DeleteServer(id string) error {
-	return c.call(func(ctx context.Context) error {
+func (c *Client) DeleteServer(ctx context.Context, id string) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		_, err := c.server.Delete(ctx, &pb.ServerIDRequest{Id: id})
 		return err
 	})
+	c.purgeServers()
+	return err
 }
 
-func (c *Client) UpdateInterface(req *pb.InterfaceRequest) (*WireGuardInterface, error) {
+func (c *Client) UpdateInterface(ctx context.Context, req *pb.InterfaceRequest) (*WireGuardInterface, error) {
 
 	var out *WireGuardInterface
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.server.UpdateInterface(ctx, req)
 		if err != nil {
 			return err
 		}
 		i := res.GetInterface()
+		if i == nil {
+			return fmt.Errorf("%w: interface", ErrEmptyResponse)
+		}
 		out = &WireGuardInterface{
 			Name:        i.GetName(),
 			DisplayName: i.GetDisplayName(),
@@ -77,12 +148,20 @@ func (c *Client) UpdateInterface(req *pb.InterfaceRequest) (*WireGuardInterface,
 		}
 		return nil
 	})
+	c.purgeServers()
 	return out, err
 }
 
-func (c *Client) DeleteInterface(req *pb.InterfaceRequest) error {
-	return c.call(func(ctx context.Context) error {
+func (c *Client) DeleteInterface(ctx context.Context, req *pb.InterfaceRequest) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		_, err := c.server.DeleteInterface(ctx, req)
 		return err
 	})
+	c.purgeServers()
+	return err
+}
+
+func (c *Client) purgeServers() {
+	c.serverList.Purge()
+	c.serverByID.Purge()
 }
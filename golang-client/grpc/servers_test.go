@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+func TestListAllInterfaces(t *testing.T) {
+	b := &fakeBackend{
+		listServers: func() (*pb.ListServersResponse, error) {
+			return &pb.ListServersResponse{Servers: []*pb.Server{
+				{Id: "a", Interfaces: []*pb.WireGuardInterface{{Name: "wg0", Endpoint: "vpn.example.com", Port: 51820}}},
+				{Id: "b"},
+				{Id: "c", Interfaces: []*pb.WireGuardInterface{{Name: "wg0", Endpoint: "VPN.example.com", Port: 51820}}},
+			}}, nil
+		},
+	}
+	c := b.client(t)
+
+	got, err := c.ListAllInterfaces(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, si := range got {
+		keys = append(keys, si.ServerID+"/"+si.Interface.Name)
+	}
+	if want := []string{"a/wg0", "c/wg0"}; !slices.Equal(keys, want) {
+		t.Errorf("interfaces = %v, want %v", keys, want)
+	}
+	if dup := DuplicateEndpoints(got); len(dup["vpn.example.com:51820"]) != 2 {
+		t.Errorf("DuplicateEndpoints = %v, want a and c sharing vpn.example.com:51820", dup)
+	}
+}
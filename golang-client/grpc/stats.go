@@ -0,0 +1,28 @@
+package grpc
+
+import "sync/atomic"
+
+type Stats struct {
+	InFlight      int64
+	TotalCalls    int64
+	TotalErrors   int64
+	TotalRelogins int64
+}
+
+type stats struct {
+	inFlight      atomic.Int64
+	totalCalls    atomic.Int64
+	totalErrors   atomic.Int64
+	totalRelogins atomic.Int64
+}
+
+// Stats returns a snapshot of the call counters. Calls made through batch
+// helpers are counted individually.
+func (c *Client) Stats() Stats {
+	return Stats{
+		InFlight:      c.stats.inFlight.Load(),
+		TotalCalls:    c.stats.totalCalls.Load(),
+		TotalErrors:   c.stats.totalErrors.Load(),
+		TotalRelogins: c.stats.totalRelogins.Load(),
+	}
+}
@@ -0,0 +1,47 @@
+package grpc
+
+import "fmt"
+
+// UserStatus is a user's state as the API's status filter names it.
+type UserStatus string
+
+const (
+	StatusActive    UserStatus = "active"
+	StatusDisabled  UserStatus = "disabled"
+	StatusExpired   UserStatus = "expired"
+	StatusOverQuota UserStatus = "limited"
+
+	// StatusOnHold filters users whose duration hasn't started as they never
+	// connected. Status reports such users as active.
+	StatusOnHold UserStatus = "on_hold"
+)
+
+// Valid reports whether s is one of the status filter's wire values.
+func (s UserStatus) Valid() bool {
+	switch s {
+	case StatusActive, StatusDisabled, StatusExpired, StatusOverQuota, StatusOnHold:
+		return true
+	}
+	return false
+}
+
+// Status derives the user's status from Active, expiry and quota, in that
+// order: a disabled user is disabled even when also expired.
+func (u *User) Status() UserStatus {
+	switch {
+	case !u.Active:
+		return StatusDisabled
+	case u.isExpired(now()):
+		return StatusExpired
+	case u.IsOverQuota():
+		return StatusOverQuota
+	}
+	return StatusActive
+}
+
+func validateStatus(s string) error {
+	if !UserStatus(s).Valid() {
+		return fmt.Errorf("%w: %q", ErrInvalidStatus, s)
+	}
+	return nil
+}
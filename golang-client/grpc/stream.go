@@ -0,0 +1,35 @@
+package grpc
+
+import "context"
+
+// StreamUsers pages through the users matching opt in the background and
+// sends them one by one. Both channels are closed when paging ends; a
+// failure, including ctx ending, is sent on the error channel first.
+func (c *Client) StreamUsers(ctx context.Context, opt ListUsersOptions) (<-chan *User, <-chan error) {
+	users := make(chan *User)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(users)
+
+		it := c.UsersIterator(opt)
+		for {
+			u, ok, err := it.Next(ctx)
+			if err != nil {
+				errc <- err
+				return
+			}
+			if !ok {
+				return
+			}
+			select {
+			case users <- u:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return users, errc
+}
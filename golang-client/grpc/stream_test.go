@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+func TestStreamUsersStopsOnCancel(t *testing.T) {
+	b := &fakeBackend{listUsers: usersPages([]*pb.User{{UserKey: "a"}, {UserKey: "b"}, {UserKey: "c"}}, 3)}
+	c := b.client(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	users, errc := c.StreamUsers(ctx, ListUsersOptions{Limit: 3})
+	if u, ok := <-users; !ok || u.UserKey != "a" {
+		t.Fatalf("first user = %v, %v; want a", u, ok)
+	}
+
+	// the consumer stops reading while the next user is pending
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("paging goroutine still running after cancel")
+	}
+	if _, ok := <-users; ok {
+		t.Error("users channel still open")
+	}
+	if _, ok := <-errc; ok {
+		t.Error("error channel still open")
+	}
+}
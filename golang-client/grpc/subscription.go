@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/sublink"
+)
+
+// SubscriptionURL returns the user's public subscription link under base,
+// the REST API root such as "https://panel.example.com/api". It fails with
+// ErrNoSubToken when the user has no token.
+func (u *User) SubscriptionURL(base string) (string, error) {
+	if u.SubToken == "" {
+		return "", fmt.Errorf("%w: %s", ErrNoSubToken, u.UserKey)
+	}
+	return sublink.Link(base, u.SubToken), nil
+}
+
+// SubscriptionURL fetches the user and returns their subscription link
+// under Config.SubscriptionBaseURL. It fails with ErrNoSubscriptionBase
+// when that isn't set.
+func (c *Client) SubscriptionURL(ctx context.Context, userKey string) (string, error) {
+	if c.cfg.SubscriptionBaseURL == "" {
+		return "", ErrNoSubscriptionBase
+	}
+	u, err := c.GetUser(ctx, userKey)
+	if err != nil {
+		return "", err
+	}
+	return u.SubscriptionURL(c.cfg.SubscriptionBaseURL)
+}
+
+// subscriptionURL returns the public /sub link for tok, or "" without one or
+// without a SubscriptionBaseURL.
+func (c *Client) subscriptionURL(tok string) string {
+	if tok == "" || c.cfg.SubscriptionBaseURL == "" {
+		return ""
+	}
+	return sublink.Link(c.cfg.SubscriptionBaseURL, tok)
+}
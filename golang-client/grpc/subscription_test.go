@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"github.com/wyronapp/wyron-public/golang-client/rest"
+)
+
+func TestUserSubscriptionURL(t *testing.T) {
+	u := &User{UserKey: "k", SubToken: "a/b c"}
+	got, err := u.SubscriptionURL("https://panel.example.com/api/")
+	if want := "https://panel.example.com/api/sub/a%2Fb%20c"; err != nil || got != want {
+		t.Errorf("SubscriptionURL() = %q, %v; want %q", got, err, want)
+	}
+	_, err = (&User{UserKey: "k"}).SubscriptionURL("https://panel.example.com/api")
+	if !errors.Is(err, ErrNoSubToken) || !errors.Is(err, rest.ErrNoSubToken) {
+		t.Errorf("no token: err = %v, want ErrNoSubToken of both packages", err)
+	}
+}
+
+func TestClientSubscriptionURL(t *testing.T) {
+	b := &fakeBackend{getUser: func(*pb.UserKeyRequest) (*pb.User, error) {
+		return &pb.User{UserKey: "k", SubToken: "tok"}, nil
+	}}
+	ctx := context.Background()
+
+	c := b.client(t)
+	if _, err := c.SubscriptionURL(ctx, "k"); !errors.Is(err, ErrNoSubscriptionBase) {
+		t.Errorf("no SubscriptionBaseURL: err = %v, want ErrNoSubscriptionBase", err)
+	}
+
+	c = b.client(t, func(c *Config) { c.SubscriptionBaseURL = "https://panel.example.com/api" })
+	got, err := c.SubscriptionURL(ctx, "k")
+	if want := "https://panel.example.com/api/sub/tok"; err != nil || got != want {
+		t.Errorf("SubscriptionURL = %q, %v; want %q", got, err, want)
+	}
+}
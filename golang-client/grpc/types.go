@@ -1,22 +1,62 @@
 package grpc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"github.com/wyronapp/wyron-public/golang-client/internal/retry"
+	"github.com/wyronapp/wyron-public/golang-client/internal/sublink"
+	"github.com/wyronapp/wyron-public/golang-client/internal/wgconfig"
 )
 
 var (
 	ErrPeerNoClient        = errors.New("peer has no client bound")
+	ErrPeerNoServer        = errors.New("peer has no server assigned")
 	ErrInterfaceNotFound   = errors.New("interface not found")
 	ErrInterfaceMissingKey = errors.New("interface missing key")
+	ErrServerNoInterfaces  = errors.New("server has no interfaces")
+	ErrEmptyResponse       = errors.New("empty response")
+	ErrUserNoPeers         = errors.New("user has no peers")
+	ErrNoSubToken          = sublink.ErrNoToken
+	ErrNoSubscriptionBase  = errors.New("SubscriptionBaseURL not set")
+	ErrUnsupported         = errors.New("not supported by the server")
+	ErrAuthFailed          = errors.New("authentication failed")
+	ErrMissingCredentials  = errors.New("username and password required")
+	ErrUnavailable         = errors.New("server unavailable")
+	ErrUnauthenticated     = errors.New("token rejected")
+	ErrLoginNoToken        = errors.New("login succeeded without a token")
+	ErrTokenNotJWT         = errors.New("token is not a JWT")
+	ErrAuthLoop            = errors.New("too many re-logins")
+	ErrAccountDisabled     = errors.New("account disabled")
+	ErrUnknownMetric       = errors.New("unknown metric")
+	ErrConflict            = errors.New("conflicting update")
+	ErrInvalidKey          = errors.New("invalid key")
+	ErrInvalidAddress      = errors.New("invalid address")
+	ErrInvalidOption       = errors.New("invalid config option")
+	ErrInvalidStatus       = errors.New("invalid user status")
 )
 
+// ServerResolver looks up servers by ID; *Client implements it.
 type ServerResolver interface {
-	GetServer(id string) (*Server, error)
+	GetServer(ctx context.Context, id string) (*Server, error)
 }
 
+var _ ServerResolver = (*Client)(nil)
+
+// ConfigOptions tunes the generated wg-quick config; it is shared with the
+// other transport so both render the same file.
+type ConfigOptions = wgconfig.Options
+
+// RetryPolicy configures Config.Retry; it is shared with the other
+// transport.
+type RetryPolicy = retry.Policy
+
+// Peer is a peer resolved against its interface, as produced by
+// NormalizePeer; it is the same type for both transports.
+type Peer = wgconfig.Peer
+
 type PeerState struct {
 	ServerID       string
 	Interface      string
@@ -35,6 +75,11 @@ type WireGuardInterface struct {
 	Port        int32
 	PublicKey   string
 	CreatedAt   int64
+
+	// AllowedIPs is the interface's recommended routing, e.g. split-tunnel
+	// subnets. The server proto has no such field yet, so it stays empty
+	// and configs route everything unless ConfigOptions.AllowedIPs is set.
+	AllowedIPs []string
 }
 
 type Server struct {
@@ -44,6 +89,50 @@ type Server struct {
 	DisplayName string
 	CreatedAt   int64
 	Interfaces  []WireGuardInterface
+
+	// Region, Country and Flag are not in the server proto yet and stay
+	// empty until the backend adds them.
+	Region  string
+	Country string
+	Flag    string
+}
+
+type ServerInterface struct {
+	ServerID  string
+	Interface WireGuardInterface
+}
+
+// PublicServer is the subset of Server that is safe to show to end users.
+type PublicServer struct {
+	Name        string
+	DisplayName string
+	Region      string
+	Country     string
+	Flag        string
+	Interfaces  []PublicInterface
+}
+
+type PublicInterface struct {
+	Name        string
+	DisplayName string
+}
+
+func (s *Server) Public() PublicServer {
+	ifaces := make([]PublicInterface, 0, len(s.Interfaces))
+	for _, i := range s.Interfaces {
+		ifaces = append(ifaces, PublicInterface{
+			Name:        i.Name,
+			DisplayName: i.DisplayName,
+		})
+	}
+	return PublicServer{
+		Name:        s.Name,
+		DisplayName: s.DisplayName,
+		Region:      s.Region,
+		Country:     s.Country,
+		Flag:        s.Flag,
+		Interfaces:  ifaces,
+	}
 }
 
 type User struct {
@@ -61,9 +150,9 @@ type User struct {
 	Peers            []*PeerState
 }
 
-func (c *Client) parseServer(s *pb.Server) *Server {
+func (c *Client) parseServer(s *pb.Server) (*Server, error) {
 	if s == nil {
-		return nil
+		return nil, fmt.Errorf("%w: server", ErrEmptyResponse)
 	}
 	ifaces := make([]WireGuardInterface, 0, len(s.GetInterfaces()))
 	for _, i := range s.GetInterfaces() {
@@ -86,12 +175,12 @@ func (c *Client) parseServer(s *pb.Server) *Server {
 		DisplayName: s.GetDisplayName(),
 		CreatedAt:   s.GetCreatedAt(),
 		Interfaces:  ifaces,
-	}
+	}, nil
 }
 
-func (c *Client) parseUser(u *pb.User) *User {
+func (c *Client) parseUser(u *pb.User) (*User, error) {
 	if u == nil {
-		return nil
+		return nil, fmt.Errorf("%w: user", ErrEmptyResponse)
 	}
 	peers := make([]*PeerState, 0, len(u.GetPeers()))
 	for _, p := range u.GetPeers() {
@@ -119,20 +208,28 @@ func (c *Client) parseUser(u *pb.User) *User {
 		LastConnectedAt:  u.GetLastConnectedAt(),
 		CreatedBy:        u.GetCreatedBy(),
 		Peers:            peers,
-	}
+	}, nil
 }
 
-func (p *PeerState) resolveServer() (*Server, error) {
+func (p *PeerState) resolveServer(ctx context.Context) (*Server, error) {
 	if p.client == nil {
 		return nil, ErrPeerNoClient
 	}
-	return p.client.GetServer(p.ServerID)
+	if p.ServerID == "" {
+		return nil, ErrPeerNoServer
+	}
+	return p.client.GetServer(ctx, p.ServerID)
 }
 
-func (p *PeerState) resolveInterface() (*WireGuardInterface, error) {
-	server, err := p.resolveServer()
-	if err != nil {
-		return nil, err
+func (p *PeerState) resolveInterface(ctx context.Context, server *Server) (*WireGuardInterface, error) {
+	if server == nil {
+		var err error
+		if server, err = p.resolveServer(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if len(server.Interfaces) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrServerNoInterfaces, p.ServerID)
 	}
 
 	for i := range server.Interfaces {
@@ -148,41 +245,97 @@ func (p *PeerState) resolveInterface() (*WireGuardInterface, error) {
 	)
 }
 
-func (p *PeerState) GenerateConfig() (string, error) {
+// Addresses splits AllowedAddress, which holds one address per family on a
+// dual-stack peer, e.g. "10.0.0.2/32, fd00::2/128".
+func (p *PeerState) Addresses() ([]string, error) {
+	addrs, err := wgconfig.ParseAddresses(p.AllowedAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	return addrs, nil
+}
+
+func (p *PeerState) GenerateConfig(ctx context.Context) (string, error) {
+	return p.GenerateConfigWithOptions(ctx, ConfigOptions{})
+}
 
+func (p *PeerState) GenerateConfigWithOptions(ctx context.Context, opts ConfigOptions) (string, error) {
+	return p.generateConfig(ctx, nil, opts)
+}
+
+// generateConfig renders the config against server, or the peer's server
+// fetched through its client when nil.
+func (p *PeerState) generateConfig(ctx context.Context, server *Server, opts ConfigOptions) (string, error) {
+	// a peer without a key can't get a config whatever its server says
 	if p.PrivateKey == "" {
 		return "", ErrInterfaceMissingKey
 	}
+	iface, err := p.resolveInterface(ctx, server)
+	if err != nil {
+		return "", err
+	}
+	return p.renderConfig(iface, opts)
+}
 
-	iface, err := p.resolveInterface()
+// renderConfig renders the config against an already resolved interface.
+func (p *PeerState) renderConfig(iface *WireGuardInterface, opts ConfigOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidOption, err)
+	}
+	peer, err := p.peer(iface)
 	if err != nil {
 		return "", err
 	}
+	return wgconfig.Render(peer, opts), nil
+}
+
+// NormalizePeer resolves p against its server, fetched through the peer's
+// client, into the transport-agnostic shape configs are rendered from.
+func NormalizePeer(ctx context.Context, p *PeerState) (Peer, error) {
+	return p.normalize(ctx, nil)
+}
 
-	if iface.Endpoint == "" {
-		return "", fmt.Errorf("%w: endpoint missing", ErrInterfaceMissingKey)
+func (p *PeerState) normalize(ctx context.Context, server *Server) (Peer, error) {
+	if p.PrivateKey == "" {
+		return Peer{}, ErrInterfaceMissingKey
 	}
-	if iface.PublicKey == "" {
-		return "", fmt.Errorf("%w: public_key missing", ErrInterfaceMissingKey)
+	iface, err := p.resolveInterface(ctx, server)
+	if err != nil {
+		return Peer{}, err
 	}
+	return p.peer(iface)
+}
 
-	cfg := fmt.Sprintf(`[Interface]
-Address = %s
-DNS = %s
-PrivateKey = %s
+func (p *PeerState) peer(iface *WireGuardInterface) (Peer, error) {
+	switch {
+	case p.PrivateKey == "":
+		return Peer{}, ErrInterfaceMissingKey
+	case iface.Endpoint == "":
+		return Peer{}, fmt.Errorf("%w: endpoint missing", ErrInterfaceMissingKey)
+	case iface.PublicKey == "":
+		return Peer{}, fmt.Errorf("%w: public_key missing", ErrInterfaceMissingKey)
+	case iface.Port == 0:
+		return Peer{}, fmt.Errorf("%w: port missing", ErrInterfaceMissingKey)
+	}
 
-[Peer]
-AllowedIPs = 0.0.0.0/0
-Endpoint = %s:%d
-PublicKey = %s
-`,
-		p.AllowedAddress,
-		iface.DNS,
-		p.PrivateKey,
-		iface.Endpoint,
-		iface.Port,
-		iface.PublicKey,
-	)
+	addrs, err := p.Addresses()
+	if err != nil {
+		return Peer{}, err
+	}
+	allowed, err := wgconfig.ParseAllowedIPs(iface.AllowedIPs)
+	if err != nil {
+		return Peer{}, fmt.Errorf("interface %s: %w", iface.Name, err)
+	}
 
-	return cfg, nil
+	return Peer{
+		ServerID:   p.ServerID,
+		Interface:  p.Interface,
+		Addresses:  addrs,
+		PrivateKey: p.PrivateKey,
+		DNS:        iface.DNS,
+		Endpoint:   iface.Endpoint,
+		Port:       int(iface.Port),
+		PublicKey:  iface.PublicKey,
+		AllowedIPs: allowed,
+	}, nil
 }
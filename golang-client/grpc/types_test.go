@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+func TestParseNilMessages(t *testing.T) {
+	var c Client
+	if _, err := c.parseServer(nil); !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("parseServer(nil) err = %v, want ErrEmptyResponse", err)
+	}
+	if _, err := c.parseUser(nil); !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("parseUser(nil) err = %v, want ErrEmptyResponse", err)
+	}
+}
+
+func TestSparseResponses(t *testing.T) {
+	b := &fakeBackend{
+		getUser: func(*pb.UserKeyRequest) (*pb.User, error) {
+			return &pb.User{UserKey: "k", Peers: []*pb.Peer{
+				{Interface: "wg0", PrivateKey: "key"},
+				{ServerId: "bare", Interface: "wg0", PrivateKey: "key"},
+			}}, nil
+		},
+		getServer: func(req *pb.ServerIDRequest) (*pb.Server, error) {
+			return &pb.Server{Id: req.GetId()}, nil
+		},
+		updateInterface: func(*pb.InterfaceRequest) (*pb.UpdateInterfaceResponse, error) {
+			return &pb.UpdateInterfaceResponse{}, nil
+		},
+	}
+	c := b.client(t)
+	ctx := context.Background()
+
+	u, err := c.GetUser(ctx, "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := u.Peers[0].GenerateConfig(ctx); !errors.Is(err, ErrPeerNoServer) {
+		t.Errorf("peer without server: err = %v, want ErrPeerNoServer", err)
+	}
+	if _, err := u.Peers[1].GenerateConfig(ctx); !errors.Is(err, ErrServerNoInterfaces) {
+		t.Errorf("server without interfaces: err = %v, want ErrServerNoInterfaces", err)
+	}
+	if _, err := c.UpdateInterface(ctx, &pb.InterfaceRequest{ServerId: "bare", Name: "wg0"}); !errors.Is(err, ErrEmptyResponse) {
+		t.Errorf("UpdateInterface err = %v, want ErrEmptyResponse", err)
+	}
+}
+
+func TestWithSecretsNilPeers(t *testing.T) {
+	u := &User{UserKey: "k", Peers: []*PeerState{{ServerID: "srv", PrivateKey: "key"}, nil}}
+
+	b, err := json.Marshal(u.WithSecrets())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct{ Peers []*PeerState }
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Peers) != 2 || got.Peers[0] == nil || got.Peers[0].PrivateKey != "key" || got.Peers[1] != nil {
+		t.Errorf("WithSecrets marshaled peers as %s", b)
+	}
+
+	for _, m := range []json.Marshaler{(*User)(nil).WithSecrets(), (*PeerState)(nil).WithSecrets()} {
+		if b, err := json.Marshal(m); err != nil || string(b) != "null" {
+			t.Errorf("nil WithSecrets() = %s, %v; want null", b, err)
+		}
+	}
+}
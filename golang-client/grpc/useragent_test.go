@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+)
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{"default", nil, "wyron-go-client"},
+		{"custom", []Option{WithUserAgent("billing-bot/1.2")}, "billing-bot/1.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &fakeBackend{listServers: func() (*pb.ListServersResponse, error) {
+				return &pb.ListServersResponse{}, nil
+			}}
+			c := b.client(t, tt.opts...)
+			if _, err := c.ListServers(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			for _, md := range b.incoming() {
+				// grpc-go appends its own product token
+				if ua := md.Get("user-agent"); len(ua) != 1 || !strings.HasPrefix(ua[0], tt.want) {
+					t.Errorf("user-agent = %q, want prefix %q", ua, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -2,30 +2,72 @@ package grpc
 
 import (
 	"context"
+	"fmt"
 
 	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// maxPageSize is the largest limit the API accepts for a user listing.
+const maxPageSize = 100
+
 type ListUsersOptions struct {
 	SocialID *int64
-	Status   *string
-	Search   *string
-	Limit    int32
-	Skip     int32
-	Sort     string
-	Order    string
+
+	// Status filters by one of the UserStatus wire values; anything else
+	// fails with ErrInvalidStatus before a call is made.
+	Status *string
+
+	Search *string
+	Limit  int32
+	Skip   int32
+	Sort   string
+	Order  string
+
+	// SortBy and OrderBy take precedence over the raw Sort and Order strings.
+	SortBy  SortColumn
+	OrderBy SortOrder
+
+	// Fields would ask for only the named user fields, but ListUsersRequest
+	// has no field mask yet, so it is ignored and full users come back.
+	Fields []string
 }
 
-func (c *Client) ListUsers(opt ListUsersOptions) ([]*User, int64, error) {
+type SortColumn string
+
+const (
+	SortByCreatedAt SortColumn = "created_at"
+	SortByUsage     SortColumn = "usage"
+	SortByUserKey   SortColumn = "user_key"
+)
+
+type SortOrder string
+
+const (
+	OrderAsc  SortOrder = "asc"
+	OrderDesc SortOrder = "desc"
+)
+
+func (c *Client) ListUsers(ctx context.Context, opt ListUsersOptions) ([]*User, int64, error) {
+	if opt.Status != nil {
+		if err := validateStatus(*opt.Status); err != nil {
+			return nil, 0, err
+		}
+	}
+	if opt.SortBy != "" {
+		opt.Sort = string(opt.SortBy)
+	}
+	if opt.OrderBy != "" {
+		opt.Order = string(opt.OrderBy)
+	}
 	if opt.Limit == 0 {
 		opt.Limit = 50
 	}
 	if opt.Sort == "" {
-		opt.Sort = "created_at"
+		opt.Sort = string(SortByCreatedAt)
 	}
 	if opt.Order == "" {
-		opt.Order = "desc"
+		opt.Order = string(OrderDesc)
 	}
 
 	req := &pb.ListUsersRequest{
@@ -47,7 +89,7 @@ func (c *Client) ListUsers(opt ListUsersOptions) ([]*User, int64, error) {
 	var users []*User
 	var count int64
 
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.user.List(ctx, req)
 		if err != nil {
 			return err
@@ -55,7 +97,11 @@ func (c *Client) ListUsers(opt ListUsersOptions) ([]*User, int64, error) {
 		count = res.GetCount()
 		users = make([]*User, 0, len(res.GetUsers()))
 		for _, u := range res.GetUsers() {
-			users = append(users, c.parseUser(u))
+			user, err := c.parseUser(u)
+			if err != nil {
+				return err
+			}
+			users = append(users, user)
 		}
 		return nil
 	})
@@ -63,76 +109,109 @@ func (c *Client) ListUsers(opt ListUsersOptions) ([]*User, int64, error) {
 	return users, count, err
 }
 
-func (c *Client) GetUser(userKey string) (*User, error) {
+func (c *Client) GetUser(ctx context.Context, userKey string) (*User, error) {
 	var out *User
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.user.Get(ctx, &pb.UserKeyRequest{UserKey: userKey})
 		if err != nil {
 			return err
 		}
-		out = c.parseUser(res)
-		return nil
+		out, err = c.parseUser(res)
+		return err
 	})
 	return out, err
 }
 
-func (c *Client) CreateUser(req *pb.CreateUserRequest) (*User, error) {
+func (c *Client) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*User, error) {
 	var out *User
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.user.Create(ctx, req)
 		if err != nil {
 			return err
 		}
-		out = c.parseUser(res)
-		return nil
+		out, err = c.parseUser(res)
+		return err
 	})
 	return out, err
 }
 
-func (c *Client) EditUser(req *pb.EditUserRequest) (*User, error) {
+func (c *Client) CreateUserWithParams(ctx context.Context, params CreateUserParams) (*User, error) {
+	if err := validationError(params.Validate()); err != nil {
+		return nil, err
+	}
+	return c.CreateUser(ctx, params.toProto())
+}
+
+// CreateUserWithConfig creates a user with access to interfaceName on
+// serverID, added to params.ServerAccess when missing, and renders that
+// peer's config. When the config can't be built the created user is still
+// returned with the error, so callers can finish or undo the onboarding.
+func (c *Client) CreateUserWithConfig(ctx context.Context, params CreateUserParams, serverID, interfaceName string, opts ConfigOptions) (*User, string, error) {
+	params.ServerAccess = withAccess(params.ServerAccess, serverID, interfaceName)
+	user, err := c.CreateUserWithParams(ctx, params)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, p := range user.Peers {
+		if p.ServerID == serverID && p.Interface == interfaceName {
+			conf, err := p.GenerateConfigWithOptions(ctx, opts)
+			return user, conf, err
+		}
+	}
+	return user, "", fmt.Errorf("%w: %s on server %s", ErrUserNoPeers, interfaceName, serverID)
+}
+
+func (c *Client) EditUser(ctx context.Context, req *pb.EditUserRequest) (*User, error) {
 	var out *User
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.user.Edit(ctx, req)
 		if err != nil {
 			return err
 		}
-		out = c.parseUser(res)
-		return nil
+		out, err = c.parseUser(res)
+		return err
 	})
 	return out, err
 }
 
-func (c *Client) DeleteUser(userKey string) error {
-	return c.call(func(ctx context.Context) error {
+func (c *Client) EditUserWithParams(ctx context.Context, userKey string, params EditUserParams) (*User, error) {
+	if err := validationError(params.Validate()); err != nil {
+		return nil, err
+	}
+	return c.EditUser(ctx, params.toProto(userKey))
+}
+
+func (c *Client) DeleteUser(ctx context.Context, userKey string) error {
+	return c.call(ctx, func(ctx context.Context) error {
 		_, err := c.user.Delete(ctx, &pb.UserKeyRequest{UserKey: userKey})
 		return err
 	})
 }
 
-func (c *Client) EnableUser(userKey string) error {
-	return c.call(func(ctx context.Context) error {
+func (c *Client) EnableUser(ctx context.Context, userKey string) error {
+	return c.call(ctx, func(ctx context.Context) error {
 		_, err := c.user.Enable(ctx, &pb.UserKeyRequest{UserKey: userKey})
 		return err
 	})
 }
 
-func (c *Client) DisableUser(userKey string) error {
-	return c.call(func(ctx context.Context) error {
+func (c *Client) DisableUser(ctx context.Context, userKey string) error {
+	return c.call(ctx, func(ctx context.Context) error {
 		_, err := c.user.Disable(ctx, &pb.UserKeyRequest{UserKey: userKey})
 		return err
 	})
 }
 
-func (c *Client) ResetUsage(userKey string) error {
-	return c.call(func(ctx context.Context) error {
+func (c *Client) ResetUsage(ctx context.Context, userKey string) error {
+	return c.call(ctx, func(ctx context.Context) error {
 		_, err := c.user.ResetUsage(ctx, &pb.UserKeyRequest{UserKey: userKey})
 		return err
 	})
 }
 
-func (c *Client) Metrics() (*pb.MetricsResponse, error) {
+func (c *Client) Metrics(ctx context.Context) (*pb.MetricsResponse, error) {
 	var out *pb.MetricsResponse
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.user.Metrics(ctx, &emptypb.Empty{})
 		if err != nil {
 			return err
@@ -143,15 +222,46 @@ func (c *Client) Metrics() (*pb.MetricsResponse, error) {
 	return out, err
 }
 
-func (c *Client) RevokeSubToken(userKey string) (*User, error) {
+func (c *Client) RevokeSubToken(ctx context.Context, userKey string) (*User, error) {
 	var out *User
-	err := c.call(func(ctx context.Context) error {
+	err := c.call(ctx, func(ctx context.Context) error {
 		res, err := c.user.RevokeSubToken(ctx, &pb.UserKeyRequest{UserKey: userKey})
 		if err != nil {
 			return err
 		}
-		out = c.parseUser(res)
-		return nil
+		out, err = c.parseUser(res)
+		return err
 	})
 	return out, err
 }
+
+// SetUserNote would attach a free-form note to a user. The Wyron API stores no
+// notes or labels, so it always fails with ErrUnsupported.
+func (c *Client) SetUserNote(ctx context.Context, userKey, note string) (string, error) {
+	return "", fmt.Errorf("%w: user notes for %q", ErrUnsupported, userKey)
+}
+
+// FindDuplicateSocialIDs pages through all users and returns the social IDs
+// shared by more than one user, with the keys of those users.
+func (c *Client) FindDuplicateSocialIDs(ctx context.Context) (map[int64][]string, error) {
+	keys := make(map[int64][]string)
+	it := c.UsersIterator(ListUsersOptions{Limit: maxPageSize, SortBy: SortByCreatedAt, OrderBy: OrderAsc})
+	for {
+		u, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		keys[u.SocialID] = append(keys[u.SocialID], u.UserKey)
+	}
+
+	out := make(map[int64][]string)
+	for id, ks := range keys {
+		if len(ks) > 1 {
+			out[id] = ks
+		}
+	}
+	return out, nil
+}
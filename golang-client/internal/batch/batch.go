@@ -0,0 +1,54 @@
+package batch
+
+import (
+	"context"
+	"sync"
+)
+
+// Run calls fn for every key on at most concurrency workers. Once ctx is
+// canceled no further keys are started; the results of the keys that already
+// ran are returned together with ctx.Err().
+func Run(ctx context.Context, keys []string, concurrency int, fn func(ctx context.Context, key string) error) (map[string]error, error) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if concurrency > len(keys) {
+		concurrency = len(keys)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(keys))
+		jobs    = make(chan string)
+	)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				err := fn(ctx, key)
+				mu.Lock()
+				results[key] = err
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- key:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, ctx.Err()
+}
@@ -0,0 +1,70 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunAll(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+	var running, peak atomic.Int32
+	fail := errors.New("fail")
+	results, err := Run(context.Background(), keys, 2, func(_ context.Context, key string) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if key == "c" {
+			return fail
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(keys) {
+		t.Fatalf("got %d results, want %d", len(results), len(keys))
+	}
+	for _, k := range keys {
+		if got, want := results[k], k == "c"; (got != nil) != want || want && !errors.Is(got, fail) {
+			t.Errorf("%s: err = %v", k, got)
+		}
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("%d calls ran at once, want at most 2", p)
+	}
+}
+
+func TestRunCanceledKeepsPartialResults(t *testing.T) {
+	keys := make([]string, 10)
+	for i := range keys {
+		keys[i] = fmt.Sprint(i)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results, err := Run(ctx, keys, 1, func(_ context.Context, key string) error {
+		if key == "2" {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	for _, k := range []string{"0", "1", "2"} {
+		if e, ok := results[k]; !ok || e != nil {
+			t.Errorf("%s: result = %v, %v; want a nil error", k, e, ok)
+		}
+	}
+	if len(results) >= len(keys) {
+		t.Errorf("all %d keys ran after cancel", len(results))
+	}
+}
@@ -0,0 +1,68 @@
+package creds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Set is a username and password pair carried by a context.
+type Set struct {
+	Username string
+	Password string
+}
+
+type ctxKey struct{}
+
+// With returns ctx carrying s.
+func With(ctx context.Context, s Set) context.Context {
+	return context.WithValue(ctx, ctxKey{}, s)
+}
+
+// From returns the credentials carried by ctx, if any.
+func From(ctx context.Context) (Set, bool) {
+	s, ok := ctx.Value(ctxKey{}).(Set)
+	return s, ok
+}
+
+// Key returns an opaque identity for the credential set carried by ctx, or
+// "" when it carries none, for keying data that must not cross sets. The
+// password is hashed so it is not kept in the clear.
+func Key(ctx context.Context) string {
+	s, ok := From(ctx)
+	if !ok {
+		return ""
+	}
+	h := sha256.Sum256([]byte(s.Username + "\x00" + s.Password))
+	return hex.EncodeToString(h[:])
+}
+
+// Token is a token and its expiry, the zero time when unknown.
+type Token struct {
+	Value string
+	Exp   time.Time
+}
+
+// Store keeps one token per credential set. Entries are never evicted, so
+// it grows with the number of distinct sets used.
+type Store struct {
+	mu sync.Mutex
+	m  map[Set]Token
+}
+
+func (s *Store) Get(k Set) Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.m[k]
+}
+
+func (s *Store) Put(k Set, t Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.m == nil {
+		s.m = make(map[Set]Token)
+	}
+	s.m[k] = t
+}
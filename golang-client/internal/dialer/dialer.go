@@ -0,0 +1,47 @@
+package dialer
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// Dialer pins the hosts listed in Hosts to a fixed IP, bypassing DNS for
+// them, and dials everything else through the embedded net.Dialer and its
+// Resolver.
+type Dialer struct {
+	*net.Dialer
+	Hosts map[string]string
+}
+
+// ValidateHosts checks that every static mapping points at an IP literal.
+func ValidateHosts(hosts map[string]string) error {
+	for host, ip := range hosts {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid static host %q: %q is not an IP address", host, ip)
+		}
+	}
+	return nil
+}
+
+func (d *Dialer) Rewrite(addr string) string {
+	if len(d.Hosts) == 0 {
+		return addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip, ok := d.Hosts[host]; ok {
+		return net.JoinHostPort(ip, port)
+	}
+	return addr
+}
+
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d.Dialer.DialContext(ctx, network, d.Rewrite(addr))
+}
+
+func (d *Dialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
@@ -0,0 +1,97 @@
+// Package op names API operations alike for both transports, e.g.
+// "users.List" after the gRPC method, for tracing and metrics.
+package op
+
+import (
+	"net/http"
+	"strings"
+)
+
+var services = map[string]string{
+	"auth.AuthService":     "auth",
+	"server.ServerService": "servers",
+	"user.UserService":     "users",
+}
+
+// FromGRPC names a call by its full method, e.g. "/user.UserService/List".
+func FromGRPC(fullMethod string) string {
+	svc, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return fullMethod
+	}
+	if name, ok := services[svc]; ok {
+		svc = name
+	}
+	return svc + "." + method
+}
+
+// FromHTTP names a REST request by its method and path. The resource is the
+// first auth, users, servers or sub segment, so a base path before it is
+// skipped. Requests outside the known routes are named by method and path.
+func FromHTTP(method, path string) string {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, res := range segs {
+		switch res {
+		case "auth", "users", "servers", "sub":
+			if name := route(method, res, segs[i+1:]); name != "" {
+				return res + "." + name
+			}
+			return res + "." + method
+		}
+	}
+	return method + " " + path
+}
+
+func route(method, res string, tail []string) string {
+	switch res {
+	case "auth":
+		if len(tail) == 1 {
+			return camel(tail[0])
+		}
+	case "sub":
+		if len(tail) == 1 && method == http.MethodGet {
+			return "Get"
+		}
+	case "users":
+		switch {
+		case len(tail) == 0:
+			return pick(method, map[string]string{http.MethodGet: "List", http.MethodPost: "Create"})
+		case len(tail) == 1 && tail[0] == "metrics" && method == http.MethodGet:
+			return "Metrics"
+		case len(tail) == 1:
+			return pick(method, map[string]string{http.MethodGet: "Get", http.MethodPatch: "Edit", http.MethodDelete: "Delete"})
+		case len(tail) == 2 && method == http.MethodPost:
+			if tail[1] == "revoke-sub" {
+				return "RevokeSubToken"
+			}
+			return camel(tail[1])
+		}
+	case "servers":
+		switch {
+		case len(tail) == 0:
+			return pick(method, map[string]string{http.MethodGet: "List", http.MethodPost: "Update"})
+		case len(tail) == 1:
+			return pick(method, map[string]string{http.MethodGet: "Get", http.MethodDelete: "Delete"})
+		case len(tail) == 2 && tail[1] == "interfaces" && method == http.MethodPost:
+			return "UpdateInterface"
+		case len(tail) == 3 && tail[1] == "interfaces" && method == http.MethodDelete:
+			return "DeleteInterface"
+		}
+	}
+	return ""
+}
+
+func pick(method string, names map[string]string) string {
+	return names[method]
+}
+
+// camel turns a path segment such as "reset-usage" into "ResetUsage".
+func camel(seg string) string {
+	var b strings.Builder
+	for _, w := range strings.Split(seg, "-") {
+		if w != "" {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	return b.String()
+}
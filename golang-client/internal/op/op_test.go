@@ -0,0 +1,50 @@
+package op
+
+import "testing"
+
+func TestFromGRPC(t *testing.T) {
+	tests := map[string]string{
+		"/user.UserService/List":    "users.List",
+		"/server.ServerService/Get": "servers.Get",
+		"/auth.AuthService/Login":   "auth.Login",
+		"/other.Service/Call":       "other.Service.Call",
+		"not-a-method":              "not-a-method",
+	}
+	for in, want := range tests {
+		if got := FromGRPC(in); got != want {
+			t.Errorf("FromGRPC(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFromHTTP(t *testing.T) {
+	tests := []struct {
+		method, path, want string
+	}{
+		{"POST", "/api/auth/login", "auth.Login"},
+		{"GET", "/api/auth/me", "auth.Me"},
+		{"GET", "/api/users", "users.List"},
+		{"POST", "/api/users", "users.Create"},
+		{"GET", "/api/users/metrics", "users.Metrics"},
+		{"GET", "/api/users/k1", "users.Get"},
+		{"PATCH", "/api/users/k1", "users.Edit"},
+		{"DELETE", "/api/users/k1", "users.Delete"},
+		{"POST", "/api/users/k1/revoke-sub", "users.RevokeSubToken"},
+		{"POST", "/api/users/k1/reset-usage", "users.ResetUsage"},
+		{"GET", "/api/servers", "servers.List"},
+		{"POST", "/api/servers", "servers.Update"},
+		{"GET", "/api/servers/s1", "servers.Get"},
+		{"DELETE", "/api/servers/s1", "servers.Delete"},
+		{"POST", "/api/servers/s1/interfaces", "servers.UpdateInterface"},
+		{"DELETE", "/api/servers/s1/interfaces/wg0", "servers.DeleteInterface"},
+		{"GET", "/api/sub/tok", "sub.Get"},
+		{"GET", "/v2/wyron/users", "users.List"},
+		{"PUT", "/api/users/k1", "users.PUT"},
+		{"GET", "/api/version", "GET /api/version"},
+	}
+	for _, tt := range tests {
+		if got := FromHTTP(tt.method, tt.path); got != tt.want {
+			t.Errorf("FromHTTP(%s, %s) = %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
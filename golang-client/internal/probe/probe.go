@@ -0,0 +1,56 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Check resolves the host of a host:port endpoint, returning how long the
+// lookup took, and sends it one UDP datagram. It fails when the host doesn't
+// resolve, there is no route to it, or it refuses the port because nothing
+// listens there. Passing is no sign of life: WireGuard never answers
+// unauthenticated packets, so a live server and a host that is down without
+// saying so look the same short of a real handshake.
+func Check(ctx context.Context, addr string, wait time.Duration) (time.Duration, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return 0, err
+	}
+	portNum, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	start := time.Now()
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return 0, err
+	}
+	resolved := time.Since(start)
+
+	conn, err := net.DialUDP("udp", nil, net.UDPAddrFromAddrPort(netip.AddrPortFrom(ips[0].Unmap(), uint16(portNum))))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte{0}); err != nil {
+		return 0, err
+	}
+
+	// a refusal comes back as an ICMP error on the next read
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+	if _, err := conn.Read(make([]byte, 1)); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		return 0, err
+	}
+	return resolved, nil
+}
@@ -0,0 +1,18 @@
+package ratelimit
+
+import "golang.org/x/time/rate"
+
+// Limiter is the token bucket both clients share their request budget
+// through. Wait fails at once, without waiting, when the next token would
+// come after ctx's deadline.
+type Limiter = rate.Limiter
+
+// New returns a limiter allowing perSecond events on average and bursts of
+// up to burst, at least 1, starting full. It returns nil for a rate of zero
+// or less, which callers treat as unlimited.
+func New(perSecond float64, burst int) *Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), max(burst, 1))
+}
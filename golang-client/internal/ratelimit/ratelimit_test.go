@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewUnlimited(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		if l := New(rate, 5); l != nil {
+			t.Errorf("New(%v) = %v, want nil", rate, l)
+		}
+	}
+}
+
+func TestBurstThenRate(t *testing.T) {
+	l := New(50, 3)
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if took := time.Since(start); took > 10*time.Millisecond {
+		t.Errorf("burst of 3 took %v, want no wait", took)
+	}
+
+	start = time.Now()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took < 10*time.Millisecond {
+		t.Errorf("4th event took %v, want about 20ms", took)
+	}
+}
+
+func TestWaitHonorsContext(t *testing.T) {
+	l := New(1, 1)
+	l.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Fatal("Wait succeeded although the next token comes after the deadline")
+	}
+}
+
+func TestBurstAtLeastOne(t *testing.T) {
+	if b := New(10, 0).Burst(); b != 1 {
+		t.Errorf("Burst() = %d, want 1", b)
+	}
+}
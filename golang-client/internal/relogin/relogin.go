@@ -0,0 +1,53 @@
+package relogin
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter caps how many re-logins may happen within a sliding window.
+type Limiter struct {
+	max    int
+	window time.Duration
+
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func NewLimiter(n int, window time.Duration) *Limiter {
+	return &Limiter{max: n, window: window}
+}
+
+// Allow records a re-login and reports whether it stays within the limit.
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cut := 0
+	for cut < len(l.times) && now.Sub(l.times[cut]) >= l.window {
+		cut++
+	}
+	l.times = l.times[cut:]
+	if len(l.times) >= l.max {
+		return false
+	}
+	l.times = append(l.times, now)
+	return true
+}
+
+// Wait sleeps for d unless ctx ends first.
+func Wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy retries transient failures of idempotent requests with
+// exponential backoff. The transports expose it as RetryPolicy.
+type Policy struct {
+	// MaxAttempts counts the first try too; below 2 nothing is retried.
+	MaxAttempts int
+
+	// BaseDelay (100ms by default) is waited before the first retry and
+	// doubles with each further one, up to MaxDelay (2s by default).
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Jitter randomizes each delay by up to this fraction either way, 0 to
+	// 1, so clients failing together don't retry in lockstep.
+	Jitter float64
+
+	// NonIdempotent also retries requests that may have taken effect once,
+	// such as creating a user. A retry after a lost response can then apply
+	// it twice.
+	NonIdempotent bool
+}
+
+// Enabled reports whether p retries at all.
+func (p Policy) Enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// Delay returns the wait before retry n, counting from 1.
+func (p Policy) Delay(n int) time.Duration {
+	base, ceil := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	if ceil <= 0 {
+		ceil = 2 * time.Second
+	}
+	d := base
+	for i := 1; i < n && d < ceil; i++ {
+		d *= 2
+	}
+	d = min(d, ceil)
+	if j := min(max(p.Jitter, 0), 1); j > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * j * float64(d))
+	}
+	return d
+}
+
+// Fits reports whether a wait of d ends before ctx's deadline, leaving the
+// retry a chance to finish.
+func Fits(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > d
+}
+
+// Wait sleeps for d or until ctx ends.
+func Wait(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
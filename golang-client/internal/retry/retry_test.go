@@ -0,0 +1,79 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Policy
+		want []time.Duration
+	}{
+		{"defaults", Policy{}, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, 1600 * time.Millisecond, 2 * time.Second, 2 * time.Second}},
+		{"custom", Policy{BaseDelay: time.Second, MaxDelay: 3 * time.Second}, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}},
+	}
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			if got := tt.p.Delay(i + 1); got != want {
+				t.Errorf("%s: Delay(%d) = %v, want %v", tt.name, i+1, got, want)
+			}
+		}
+	}
+}
+
+func TestDelayJitter(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, Jitter: 0.5}
+	for range 100 {
+		if d := p.Delay(1); d < 500*time.Millisecond || d > 1500*time.Millisecond {
+			t.Fatalf("Delay(1) = %v, want within 50%% of 1s", d)
+		}
+	}
+	// out-of-range jitter is clamped to 1
+	p.Jitter = 5
+	for range 100 {
+		if d := p.Delay(1); d < 0 || d > 2*time.Second {
+			t.Fatalf("Delay(1) = %v with jitter 5, want 0 to 2s", d)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	for attempts, want := range map[int]bool{0: false, 1: false, 2: true} {
+		if got := (Policy{MaxAttempts: attempts}).Enabled(); got != want {
+			t.Errorf("MaxAttempts %d: Enabled() = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestFits(t *testing.T) {
+	if !Fits(context.Background(), time.Hour) {
+		t.Error("a ctx without deadline should fit any wait")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if !Fits(ctx, 10*time.Millisecond) {
+		t.Error("10ms should fit a 1s deadline")
+	}
+	if Fits(ctx, 2*time.Second) {
+		t.Error("2s should not fit a 1s deadline")
+	}
+}
+
+func TestWait(t *testing.T) {
+	if err := Wait(context.Background(), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := Wait(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Wait did not return on cancel")
+	}
+}
@@ -0,0 +1,16 @@
+package sublink
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrNoToken is shared by both transports, which expose it as
+// ErrNoSubToken, so errors.Is matches it whichever client returned it.
+var ErrNoToken = errors.New("user has no subscription token")
+
+// Link returns the public /sub link for tok under base, the REST API root.
+func Link(base, tok string) string {
+	return strings.TrimRight(base, "/") + "/sub/" + url.PathEscape(tok)
+}
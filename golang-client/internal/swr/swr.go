@@ -0,0 +1,124 @@
+package swr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache is a stale-while-revalidate cache. A value is served as is for Fresh
+// after it was fetched; for a further Stale it is still served immediately
+// while a background fetch replaces it. Past that, callers wait for a new
+// fetch. At most one fetch per key runs at a time and concurrent callers
+// share its result. Failed fetches are not cached; a failed background fetch
+// leaves the stale value in place until it ages out.
+//
+// Fetches are shared, so they run detached from the ctx of the caller that
+// started them; a caller whose ctx ends stops waiting but the fetch goes on.
+// A nil *Cache fetches on every call.
+type Cache[V any] struct {
+	fresh time.Duration
+	stale time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*entry[V]
+}
+
+type entry[V any] struct {
+	val     V
+	fetched time.Time
+	ok      bool
+	loading *call[V]
+}
+
+type call[V any] struct {
+	done chan struct{}
+	val  V
+	err  error
+}
+
+// New returns nil, i.e. no caching, when both durations are zero.
+func New[V any](fresh, stale time.Duration) *Cache[V] {
+	if fresh <= 0 && stale <= 0 {
+		return nil
+	}
+	return &Cache[V]{
+		fresh:   fresh,
+		stale:   stale,
+		entries: make(map[string]*entry[V]),
+	}
+}
+
+func (c *Cache[V]) Get(ctx context.Context, key string, fetch func(context.Context) (V, error)) (V, error) {
+	if c == nil {
+		return fetch(ctx)
+	}
+
+	c.mu.Lock()
+	e := c.entries[key]
+	if e == nil {
+		e = &entry[V]{}
+		c.entries[key] = e
+	}
+
+	if e.ok {
+		age := time.Since(e.fetched)
+		if age < c.fresh {
+			c.mu.Unlock()
+			return e.val, nil
+		}
+		if age < c.fresh+c.stale {
+			if e.loading == nil {
+				c.load(ctx, e, fetch)
+			}
+			val := e.val
+			c.mu.Unlock()
+			return val, nil
+		}
+	}
+
+	cl := e.loading
+	if cl == nil {
+		cl = c.load(ctx, e, fetch)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.val, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// load starts a fetch for e; c.mu must be held.
+func (c *Cache[V]) load(ctx context.Context, e *entry[V], fetch func(context.Context) (V, error)) *call[V] {
+	cl := &call[V]{done: make(chan struct{})}
+	e.loading = cl
+
+	fctx := context.WithoutCancel(ctx)
+	go func() {
+		cl.val, cl.err = fetch(fctx)
+
+		c.mu.Lock()
+		if cl.err == nil {
+			e.val, e.fetched, e.ok = cl.val, time.Now(), true
+		}
+		e.loading = nil
+		c.mu.Unlock()
+
+		close(cl.done)
+	}()
+	return cl
+}
+
+// Purge drops every entry; fetches already running still complete.
+func (c *Cache[V]) Purge() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.entries = make(map[string]*entry[V])
+	c.mu.Unlock()
+}
@@ -0,0 +1,100 @@
+package swr
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetSharesFetch(t *testing.T) {
+	c := New[int](time.Minute, 0)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func(context.Context) (int, error) {
+		fetches.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	errc := make(chan error, 2)
+	for range 2 {
+		go func() {
+			v, err := c.Get(context.Background(), "k", fetch)
+			if err == nil && v != 42 {
+				err = errors.New("wrong value")
+			}
+			errc <- err
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	for range 2 {
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("fetches = %d, want 1", n)
+	}
+}
+
+func TestGetHonorsCallerContext(t *testing.T) {
+	c := New[int](time.Minute, 0)
+	release := make(chan struct{})
+	var fetchErr atomic.Value
+	fetch := func(ctx context.Context) (int, error) {
+		<-release
+		if err := ctx.Err(); err != nil {
+			fetchErr.Store(err)
+		}
+		return 7, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.Get(ctx, "k", fetch); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+
+	// the detached fetch still completes and fills the cache
+	close(release)
+	v, err := c.Get(context.Background(), "k", fetch)
+	if err != nil || v != 7 {
+		t.Fatalf("Get = %d, %v; want 7, nil", v, err)
+	}
+	if err := fetchErr.Load(); err != nil {
+		t.Fatalf("fetch saw canceled ctx: %v", err)
+	}
+}
+
+func TestGetServesStale(t *testing.T) {
+	c := New[int](time.Millisecond, time.Minute)
+	var n atomic.Int32
+	fetch := func(context.Context) (int, error) {
+		return int(n.Add(1)), nil
+	}
+	ctx := context.Background()
+	if v, _ := c.Get(ctx, "k", fetch); v != 1 {
+		t.Fatalf("first Get = %d, want 1", v)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if v, _ := c.Get(ctx, "k", fetch); v != 1 {
+		t.Fatalf("stale Get = %d, want 1", v)
+	}
+}
+
+func TestNilCacheFetches(t *testing.T) {
+	var c *Cache[int]
+	n := 0
+	fetch := func(context.Context) (int, error) {
+		n++
+		return n, nil
+	}
+	c.Get(context.Background(), "k", fetch)
+	c.Get(context.Background(), "k", fetch)
+	if n != 2 {
+		t.Fatalf("fetches = %d, want 2", n)
+	}
+}
@@ -0,0 +1,348 @@
+// Package qrdecode reads back the QR codes the tests render. It handles only
+// clean, unrotated codes with a white quiet zone and at least two pixels
+// per module, as go-qrcode draws them, and trusts every module: there is no error correction and no ECI or kanji
+// support. It lives under testdata so only tests build it.
+package qrdecode
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// Decode returns the text of the QR code in img.
+func Decode(img image.Image) (string, error) {
+	m, err := sample(img)
+	if err != nil {
+		return "", err
+	}
+	return m.decode()
+}
+
+// matrix is a sampled symbol, true for a dark module, indexed [row][col].
+type matrix [][]bool
+
+func dark(img image.Image, x, y int) bool {
+	return color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y < 128
+}
+
+// sample finds the symbol by its dark bounding box, sizes its modules from
+// the top-left finder pattern, which is 7 modules wide, and reads the
+// centre of every module.
+func sample(img image.Image) (matrix, error) {
+	b := img.Bounds()
+	minX, minY, maxX, maxY := b.Max.X, b.Max.Y, b.Min.X-1, b.Min.Y-1
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if dark(img, x, y) {
+				minX, minY = min(minX, x), min(minY, y)
+				maxX, maxY = max(maxX, x), max(maxY, y)
+			}
+		}
+	}
+	if maxX < minX {
+		return nil, errors.New("qrdecode: blank image")
+	}
+
+	// row 6 runs along the bottom edges of the top finder patterns, 7 dark
+	// modules each, and the timing pattern between them, so it changes
+	// colour 13 times fewer than it has modules
+	run := 0
+	for x := minX; x <= maxX && dark(img, x, minY); x++ {
+		run++
+	}
+	y := minY + int(6.5*float64(run)/7)
+	n := 13
+	for x := minX + 1; x <= maxX; x++ {
+		if dark(img, x, y) != dark(img, x-1, y) {
+			n++
+		}
+	}
+	if n < 21 || n > 177 || (n-17)%4 != 0 {
+		return nil, fmt.Errorf("qrdecode: no QR symbol %d modules wide", n)
+	}
+	width := float64(maxX - minX + 1)
+	step := width / float64(n)
+	height := float64(maxY-minY+1) / float64(n)
+
+	m := make(matrix, n)
+	for r := range m {
+		m[r] = make([]bool, n)
+		for c := range m[r] {
+			m[r][c] = dark(img, minX+int((float64(c)+0.5)*step), minY+int((float64(r)+0.5)*height))
+		}
+	}
+	return m, nil
+}
+
+func (m matrix) version() int { return (len(m) - 17) / 4 }
+
+// formatInfo reads the error correction level and mask pattern from the
+// copy of the format bits around the top-left finder pattern.
+func (m matrix) formatInfo() (level, mask int) {
+	var bits int
+	read := func(r, c int) {
+		bits <<= 1
+		if m[r][c] {
+			bits |= 1
+		}
+	}
+	for c := 0; c <= 5; c++ {
+		read(8, c)
+	}
+	read(8, 7)
+	read(8, 8)
+	read(7, 8)
+	for r := 5; r >= 0; r-- {
+		read(r, 8)
+	}
+	bits ^= 0x5412
+	return bits >> 13 & 3, bits >> 10 & 7
+}
+
+// alignment returns the row and column centres of the alignment patterns.
+func alignment(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	num := version/7 + 2
+	step := 26
+	if version != 32 {
+		step = (version*4 + num*2 + 1) / (num*2 - 2) * 2
+	}
+	pos := make([]int, num)
+	pos[0] = 6
+	for i, p := num-1, 17+4*version-7; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+	return pos
+}
+
+// functions marks the modules that carry no data.
+func functions(version int) matrix {
+	n := 17 + 4*version
+	f := make(matrix, n)
+	for r := range f {
+		f[r] = make([]bool, n)
+	}
+	set := func(top, left, h, w int) {
+		for r := top; r < top+h; r++ {
+			for c := left; c < left+w; c++ {
+				f[r][c] = true
+			}
+		}
+	}
+	// finder patterns with their separators and format bits
+	set(0, 0, 9, 9)
+	set(0, n-8, 9, 8)
+	set(n-8, 0, 8, 9)
+	// timing patterns
+	set(6, 9, 1, n-17)
+	set(9, 6, n-17, 1)
+
+	pos := alignment(version)
+	for i, r := range pos {
+		for j, c := range pos {
+			if i == 0 && j == 0 || i == 0 && j == len(pos)-1 || i == len(pos)-1 && j == 0 {
+				continue
+			}
+			set(r-2, c-2, 5, 5)
+		}
+	}
+	if version >= 7 {
+		set(0, n-11, 6, 3)
+		set(n-11, 0, 3, 6)
+	}
+	return f
+}
+
+func masked(mask, r, c int) bool {
+	switch mask {
+	case 0:
+		return (r+c)%2 == 0
+	case 1:
+		return r%2 == 0
+	case 2:
+		return c%3 == 0
+	case 3:
+		return (r+c)%3 == 0
+	case 4:
+		return (r/2+c/3)%2 == 0
+	case 5:
+		return r*c%2+r*c%3 == 0
+	case 6:
+		return (r*c%2+r*c%3)%2 == 0
+	default:
+		return ((r+c)%2+r*c%3)%2 == 0
+	}
+}
+
+// codewords reads the unmasked data modules in placement order: upwards
+// and downwards in turn through column pairs from the right, skipping the
+// vertical timing pattern.
+func (m matrix) codewords(mask int) []byte {
+	n := len(m)
+	f := functions(m.version())
+	var out []byte
+	var cur byte
+	bits := 0
+	up := true
+	for right := n - 1; right > 0; right -= 2 {
+		if right == 6 {
+			right--
+		}
+		for i := range n {
+			r := i
+			if up {
+				r = n - 1 - i
+			}
+			for c := right; c > right-2; c-- {
+				if f[r][c] {
+					continue
+				}
+				cur <<= 1
+				if m[r][c] != masked(mask, r, c) {
+					cur |= 1
+				}
+				if bits++; bits == 8 {
+					out = append(out, cur)
+					cur, bits = 0, 0
+				}
+			}
+		}
+		up = !up
+	}
+	return out
+}
+
+// Error correction codewords per block and block counts by version, indexed
+// by the level as the format bits number it: M, L, H, Q.
+var (
+	eccPerBlock = [4][41]int{
+		{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	}
+	eccBlocks = [4][41]int{
+		{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+		{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+		{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+		{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	}
+)
+
+// data undoes the block interleaving and returns the data codewords. The
+// first blocks are one data codeword shorter than the rest.
+func data(raw []byte, version, level int) ([]byte, error) {
+	blocks, ecc := eccBlocks[level][version], eccPerBlock[level][version]
+	short := len(raw)/blocks - ecc
+	shortBlocks := blocks - len(raw)%blocks
+	size := func(b int) int {
+		if b < shortBlocks {
+			return short
+		}
+		return short + 1
+	}
+
+	out := make([][]byte, blocks)
+	i := 0
+	for k := 0; k <= short; k++ {
+		for b := range blocks {
+			if k < size(b) {
+				if i >= len(raw) {
+					return nil, errors.New("qrdecode: too few codewords")
+				}
+				out[b] = append(out[b], raw[i])
+				i++
+			}
+		}
+	}
+	var all []byte
+	for _, b := range out {
+		all = append(all, b...)
+	}
+	return all, nil
+}
+
+// bitReader reads big-endian bit fields from the data codewords.
+type bitReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *bitReader) left() int { return len(r.b)*8 - r.pos }
+
+// read returns the next n bits, reading zeros past the end.
+func (r *bitReader) read(n int) int {
+	v := 0
+	for range n {
+		v <<= 1
+		if r.pos < len(r.b)*8 && r.b[r.pos/8]>>(7-r.pos%8)&1 == 1 {
+			v |= 1
+		}
+		r.pos++
+	}
+	return v
+}
+
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+func (m matrix) decode() (string, error) {
+	version := m.version()
+	level, mask := m.formatInfo()
+	b, err := data(m.codewords(mask), version, level)
+	if err != nil {
+		return "", err
+	}
+
+	// character count widths for numeric, alphanumeric and byte segments
+	widths := [3]int{10, 9, 8}
+	switch {
+	case version >= 27:
+		widths = [3]int{14, 13, 16}
+	case version >= 10:
+		widths = [3]int{12, 11, 16}
+	}
+
+	r := &bitReader{b: b}
+	var out []byte
+	for r.left() >= 4 {
+		switch mode := r.read(4); mode {
+		case 0:
+			return string(out), nil
+		case 1:
+			for n := r.read(widths[0]); n > 0; n -= 3 {
+				switch {
+				case n >= 3:
+					out = fmt.Appendf(out, "%03d", r.read(10))
+				case n == 2:
+					out = fmt.Appendf(out, "%02d", r.read(7))
+				default:
+					out = fmt.Appendf(out, "%d", r.read(4))
+				}
+			}
+		case 2:
+			n := r.read(widths[1])
+			for ; n >= 2; n -= 2 {
+				v := r.read(11)
+				out = append(out, alphanumeric[v/45], alphanumeric[v%45])
+			}
+			if n == 1 {
+				out = append(out, alphanumeric[r.read(6)])
+			}
+		case 4:
+			n := r.read(widths[2])
+			if r.left() < n*8 {
+				return "", errors.New("qrdecode: byte segment runs past the data")
+			}
+			for range n {
+				out = append(out, byte(r.read(8)))
+			}
+		default:
+			return "", fmt.Errorf("qrdecode: unsupported mode %d", mode)
+		}
+	}
+	return string(out), nil
+}
@@ -0,0 +1,47 @@
+package tlsconf
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// Build returns a tls.Config with the given minimum version and TLS 1.2
+// cipher suites, or nil when neither is set. Versions below TLS 1.2 and
+// suites Go considers insecure or doesn't know are rejected.
+func Build(minVersion uint16, suites []uint16) (*tls.Config, error) {
+	if minVersion == 0 && len(suites) == 0 {
+		return nil, nil
+	}
+	if minVersion != 0 && minVersion < tls.VersionTLS12 {
+		return nil, fmt.Errorf("MinTLSVersion %s: must be TLS 1.2 or later", tls.VersionName(minVersion))
+	}
+
+	known := make(map[uint16]bool)
+	for _, s := range tls.CipherSuites() {
+		known[s.ID] = true
+	}
+	for _, id := range suites {
+		if !known[id] {
+			return nil, fmt.Errorf("cipher suite %s: not a secure suite", tls.CipherSuiteName(id))
+		}
+	}
+
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+	return &tls.Config{MinVersion: minVersion, CipherSuites: suites}, nil
+}
+
+// Over returns a clone of base, or an empty config when nil, with the
+// version and suites of restrict applied when it is set.
+func Over(base, restrict *tls.Config) *tls.Config {
+	out := &tls.Config{}
+	if base != nil {
+		out = base.Clone()
+	}
+	if restrict != nil {
+		out.MinVersion = restrict.MinVersion
+		out.CipherSuites = restrict.CipherSuites
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package token
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	DefaultHeader = "Authorization"
+	DefaultScheme = "Bearer"
+)
+
+// Header applies the defaults to a configured auth header name and scheme
+// and validates both. The scheme only defaults to Bearer on the Authorization
+// header; a custom header with no scheme carries the bare token.
+func Header(name, scheme string) (string, string, error) {
+	if name == "" {
+		name = DefaultHeader
+	}
+	if !httpguts.ValidHeaderFieldName(name) {
+		return "", "", fmt.Errorf("invalid auth header name %q", name)
+	}
+	if scheme == "" && strings.EqualFold(name, DefaultHeader) {
+		scheme = DefaultScheme
+	}
+	if scheme != "" && !httpguts.ValidHeaderFieldName(scheme) {
+		return "", "", fmt.Errorf("invalid auth scheme %q", scheme)
+	}
+	return name, scheme, nil
+}
+
+// Value renders tok for a header built by Header.
+func Value(scheme, tok string) string {
+	if scheme == "" {
+		return tok
+	}
+	return scheme + " " + tok
+}
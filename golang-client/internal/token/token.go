@@ -0,0 +1,60 @@
+package token
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Claims decodes the payload of a JWT. The signature is not verified, which
+// only the issuing server can do.
+func Claims(tok string) (map[string]any, error) {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("not three dot-separated parts")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&claims); err != nil {
+		return nil, err
+	}
+	if claims == nil {
+		return nil, errors.New("empty payload")
+	}
+	return claims, nil
+}
+
+// Expiry returns the exp claim of a JWT, or the zero time when tok is not a
+// JWT or carries no exp. The signature is not verified.
+func Expiry(tok string) time.Time {
+	claims, err := Claims(tok)
+	if err != nil {
+		return time.Time{}
+	}
+	n, ok := claims["exp"].(json.Number)
+	if !ok {
+		return time.Time{}
+	}
+	exp, err := n.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(exp), 0)
+}
+
+// Expiring reports whether exp is known and falls within ahead of now.
+func Expiring(exp time.Time, ahead time.Duration) bool {
+	if exp.IsZero() || ahead <= 0 {
+		return false
+	}
+	return !time.Now().Add(ahead).Before(exp)
+}
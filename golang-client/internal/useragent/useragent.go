@@ -0,0 +1,24 @@
+package useragent
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+const module = "github.com/wyronapp/wyron-public/golang-client"
+
+// Default returns "wyron-go-client/<version>", the version being that of
+// this module as recorded in the build, when it is built as a dependency.
+var Default = sync.OnceValue(func() string {
+	ua := "wyron-go-client"
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ua
+	}
+	for _, m := range append(info.Deps, &info.Main) {
+		if m.Path == module && m.Version != "" && m.Version != "(devel)" {
+			return ua + "/" + m.Version
+		}
+	}
+	return ua
+})
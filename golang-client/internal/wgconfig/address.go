@@ -0,0 +1,58 @@
+package wgconfig
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ParseAddresses splits a comma-separated Address value, as carried by a
+// dual-stack peer, and checks each entry is an IP or a CIDR prefix.
+func ParseAddresses(s string) ([]string, error) {
+	var out []string
+	for _, a := range strings.Split(s, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		if strings.Contains(a, "/") {
+			if _, err := netip.ParsePrefix(a); err != nil {
+				return nil, err
+			}
+		} else if _, err := netip.ParseAddr(a); err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no address")
+	}
+	return out, nil
+}
+
+// ParseAllowedIPs checks each entry of an AllowedIPs list is a CIDR prefix,
+// ignoring surrounding space, and returns them in canonical form.
+func ParseAllowedIPs(ips []string) ([]string, error) {
+	var out []string
+	for _, a := range ips {
+		p, err := netip.ParsePrefix(strings.TrimSpace(a))
+		if err != nil {
+			return nil, fmt.Errorf("allowed IP %q: want a CIDR prefix", a)
+		}
+		out = append(out, p.String())
+	}
+	return out, nil
+}
+
+// hasIPv6 reports whether any of addrs, as returned by ParseAddresses, is an
+// IPv6 address or prefix.
+func hasIPv6(addrs []string) bool {
+	for _, a := range addrs {
+		a, _, _ = strings.Cut(a, "/")
+		if ip, err := netip.ParseAddr(a); err == nil && ip.Is6() && !ip.Is4In6() {
+			return true
+		}
+	}
+	return false
+}
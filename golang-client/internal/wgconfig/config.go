@@ -0,0 +1,65 @@
+package wgconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Peer is what config generation needs about a peer and its interface,
+// independent of the transport it was fetched over.
+type Peer struct {
+	ServerID   string
+	Interface  string
+	Addresses  []string
+	PrivateKey string
+
+	DNS        string
+	Endpoint   string
+	Port       int
+	PublicKey  string
+	AllowedIPs []string
+}
+
+// Render formats p as a wg-quick config. o must have passed Validate and
+// p.AllowedIPs ParseAllowedIPs.
+func Render(p Peer, o Options) string {
+	var b strings.Builder
+	b.WriteString("[Interface]\n")
+	fmt.Fprintf(&b, "Address = %s\n", strings.Join(p.Addresses, ", "))
+	// an empty DNS line is invalid; leaving it out keeps the device resolver
+	if !o.OmitDNS && p.DNS != "" {
+		fmt.Fprintf(&b, "DNS = %s\n", p.DNS)
+	}
+	fmt.Fprintf(&b, "PrivateKey = %s\n", p.PrivateKey)
+	if o.MTU != 0 {
+		fmt.Fprintf(&b, "MTU = %d\n", o.MTU)
+	}
+	if o.Table != "" {
+		fmt.Fprintf(&b, "Table = %s\n", o.Table)
+	}
+	for _, h := range o.hooks() {
+		for _, cmd := range h.cmds {
+			fmt.Fprintf(&b, "%s = %s\n", h.key, cmd)
+		}
+	}
+
+	b.WriteString("\n[Peer]\n")
+	allowed, _ := ParseAllowedIPs(o.AllowedIPs)
+	if len(allowed) == 0 {
+		allowed = p.AllowedIPs
+	}
+	if len(allowed) == 0 {
+		allowed = []string{"0.0.0.0/0"}
+		// a dual-stack peer routes IPv6 through the tunnel too, or it leaks
+		if hasIPv6(p.Addresses) {
+			allowed = append(allowed, "::/0")
+		}
+	}
+	fmt.Fprintf(&b, "AllowedIPs = %s\n", strings.Join(allowed, ", "))
+	fmt.Fprintf(&b, "Endpoint = %s:%d\n", p.Endpoint, p.Port)
+	fmt.Fprintf(&b, "PublicKey = %s\n", p.PublicKey)
+	if o.PersistentKeepalive != 0 {
+		fmt.Fprintf(&b, "PersistentKeepalive = %d\n", o.keepalive())
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package wgconfig
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRenderDefaultAllowedIPs(t *testing.T) {
+	tests := []struct {
+		name  string
+		addrs []string
+		want  string
+	}{
+		{"ipv4", []string{"10.0.0.2/32"}, "AllowedIPs = 0.0.0.0/0\n"},
+		{"ipv6", []string{"fd00::2/128"}, "AllowedIPs = 0.0.0.0/0, ::/0\n"},
+		{"dual stack", []string{"10.0.0.2/32", "fd00::2/128"}, "AllowedIPs = 0.0.0.0/0, ::/0\n"},
+		{"bare ipv6", []string{"10.0.0.2", "fd00::2"}, "AllowedIPs = 0.0.0.0/0, ::/0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := Render(Peer{Addresses: tt.addrs, Endpoint: "vpn.example.com", Port: 51820}, Options{})
+			if !strings.Contains(conf, tt.want) {
+				t.Errorf("config lacks %q:\n%s", tt.want, conf)
+			}
+		})
+	}
+}
+
+func TestRenderExplicitAllowedIPsWin(t *testing.T) {
+	p := Peer{Addresses: []string{"fd00::2/128"}, AllowedIPs: []string{"10.8.0.0/24"}}
+	if conf := Render(p, Options{}); !strings.Contains(conf, "AllowedIPs = 10.8.0.0/24\n") {
+		t.Errorf("interface routes not used:\n%s", conf)
+	}
+	if conf := Render(p, Options{AllowedIPs: []string{"192.168.0.0/16"}}); !strings.Contains(conf, "AllowedIPs = 192.168.0.0/16\n") {
+		t.Errorf("option routes not used:\n%s", conf)
+	}
+}
+
+func TestRenderNormalizesAllowedIPs(t *testing.T) {
+	p := Peer{Addresses: []string{"10.0.0.2/32"}, AllowedIPs: []string{"10.9.0.0/16"}}
+	o := Options{AllowedIPs: []string{" 10.8.0.0/24", "FD00::/8 "}}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if conf := Render(p, o); !strings.Contains(conf, "AllowedIPs = 10.8.0.0/24, fd00::/8\n") {
+		t.Errorf("option routes not normalized:\n%s", conf)
+	}
+}
+
+func TestParseAllowedIPs(t *testing.T) {
+	tests := []struct {
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{in: nil},
+		{in: []string{"10.8.0.0/24", " ::/0 "}, want: []string{"10.8.0.0/24", "::/0"}},
+		{in: []string{"10.8.0.1"}, wantErr: true},
+		{in: []string{""}, wantErr: true},
+		{in: []string{"10.8.0.0/24\nPostUp = x"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAllowedIPs(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAllowedIPs(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParseAllowedIPs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderDNS(t *testing.T) {
+	tests := []struct {
+		name string
+		dns  string
+		opts Options
+		want bool
+	}{
+		{"set", "1.1.1.1", Options{}, true},
+		{"empty", "", Options{}, false},
+		{"omitted", "1.1.1.1", Options{OmitDNS: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := Render(Peer{Addresses: []string{"10.0.0.2/32"}, DNS: tt.dns}, tt.opts)
+			if got := strings.Contains(conf, "DNS"); got != tt.want {
+				t.Errorf("DNS line present = %v, want %v:\n%s", got, tt.want, conf)
+			}
+		})
+	}
+}
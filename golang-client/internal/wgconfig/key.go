@@ -0,0 +1,37 @@
+package wgconfig
+
+import (
+	"crypto/ecdh"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// PublicKey derives the base64 Curve25519 public key from a base64
+// WireGuard private key.
+func PublicKey(priv string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(priv)
+	if err != nil {
+		return "", err
+	}
+	k, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return "", fmt.Errorf("got %d bytes, want 32", len(raw))
+	}
+	return base64.StdEncoding.EncodeToString(k.PublicKey().Bytes()), nil
+}
+
+// Fingerprint returns the first 8 bytes of the SHA-256 of a base64
+// WireGuard key as hex, short enough to read out and compare.
+func Fingerprint(key string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) != 32 {
+		return "", fmt.Errorf("got %d bytes, want 32", len(raw))
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8]), nil
+}
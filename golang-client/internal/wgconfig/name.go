@@ -0,0 +1,51 @@
+package wgconfig
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// maxIfaceName is IFNAMSIZ minus the trailing NUL.
+const maxIfaceName = 15
+
+// InterfaceName derives a wg-quick interface name from a server and interface
+// name. Names that need altering, because of characters other than letters,
+// digits and dashes or because they exceed 15 characters, are cut as needed
+// and suffixed with a short hash of the original, so the result is stable
+// and distinct inputs don't collide on the altered form.
+func InterfaceName(server, iface string) string {
+	full := server + "-" + iface
+
+	var b strings.Builder
+	dash := false
+	for _, r := range full {
+		ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		if !ok {
+			if !dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = true
+			continue
+		}
+		b.WriteRune(r)
+		dash = false
+	}
+	name := strings.TrimRight(b.String(), "-")
+
+	if name != "" && len(name) <= maxIfaceName && name == full {
+		return name
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(full))
+	suffix := fmt.Sprintf("%04x", h.Sum32()&0xffff)
+
+	if name == "" {
+		return "wg-" + suffix
+	}
+	if len(name) > maxIfaceName-len(suffix)-1 {
+		name = strings.TrimRight(name[:maxIfaceName-len(suffix)-1], "-")
+	}
+	return name + "-" + suffix
+}
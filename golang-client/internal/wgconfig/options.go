@@ -0,0 +1,101 @@
+package wgconfig
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options tunes a rendered config. The transports expose it as
+// ConfigOptions.
+type Options struct {
+	// OmitDNS drops the DNS line even when the interface has one, leaving
+	// name resolution to the device (split tunnels).
+	OmitDNS bool
+
+	// Table sets wg-quick's routing table: "off" to install no routes, "auto",
+	// or a table ID. Empty leaves the line out.
+	Table string
+
+	// MTU sets the tunnel MTU, 1280 (the IPv6 minimum) to 9000 for jumbo
+	// frames. Zero leaves the line out and wg-quick picks one.
+	MTU int
+
+	// AllowedIPs replaces the routed ranges of the [Peer] section, taking
+	// precedence over the interface's own AllowedIPs. Each entry must be a
+	// CIDR prefix such as 10.8.0.0/24; set it to split-tunnel.
+	AllowedIPs []string
+
+	// PersistentKeepalive makes the device ping the server at this interval
+	// to keep NAT mappings open. It is emitted in whole seconds, 1 to 65535;
+	// zero leaves the line out.
+	PersistentKeepalive time.Duration
+
+	// PreUp, PostUp, PreDown and PostDown are emitted as wg-quick hook lines
+	// in order. They are shell commands and not checked beyond rejecting line
+	// breaks, which would end the entry.
+	PreUp    []string
+	PostUp   []string
+	PreDown  []string
+	PostDown []string
+}
+
+// Validate reports the first option that would produce a broken config.
+func (o Options) Validate() error {
+	if o.Table != "" {
+		if err := ValidateTable(o.Table); err != nil {
+			return err
+		}
+	}
+	if o.MTU != 0 && (o.MTU < 1280 || o.MTU > 9000) {
+		return fmt.Errorf("MTU %d: want 1280 to 9000", o.MTU)
+	}
+	if _, err := ParseAllowedIPs(o.AllowedIPs); err != nil {
+		return err
+	}
+	if o.PersistentKeepalive != 0 {
+		if n := o.keepalive(); n < 1 || n > 65535 {
+			return fmt.Errorf("persistent keepalive %s: want 1s to 65535s", o.PersistentKeepalive)
+		}
+	}
+	for _, h := range o.hooks() {
+		for _, cmd := range h.cmds {
+			if strings.ContainsAny(cmd, "\r\n") {
+				return fmt.Errorf("%s contains a line break", h.key)
+			}
+		}
+	}
+	return nil
+}
+
+func (o Options) keepalive() int64 {
+	return int64(o.PersistentKeepalive / time.Second)
+}
+
+type hook struct {
+	key  string
+	cmds []string
+}
+
+func (o Options) hooks() []hook {
+	return []hook{
+		{"PreUp", o.PreUp},
+		{"PostUp", o.PostUp},
+		{"PreDown", o.PreDown},
+		{"PostDown", o.PostDown},
+	}
+}
+
+// ValidateTable accepts the wg-quick Table values: "off", "auto" or a
+// numeric routing table ID.
+func ValidateTable(v string) error {
+	switch v {
+	case "off", "auto":
+		return nil
+	}
+	if _, err := strconv.ParseUint(v, 10, 32); err != nil {
+		return fmt.Errorf("table %q: want off, auto or a table ID", v)
+	}
+	return nil
+}
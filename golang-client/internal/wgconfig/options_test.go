@@ -0,0 +1,74 @@
+package wgconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPersistentKeepalive(t *testing.T) {
+	tests := []struct {
+		name    string
+		d       time.Duration
+		want    string
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "seconds", d: 25 * time.Second, want: "PersistentKeepalive = 25\n"},
+		{name: "truncated", d: 25*time.Second + 900*time.Millisecond, want: "PersistentKeepalive = 25\n"},
+		{name: "max", d: 65535 * time.Second, want: "PersistentKeepalive = 65535\n"},
+		{name: "under a second", d: 500 * time.Millisecond, wantErr: true},
+		{name: "negative", d: -time.Second, wantErr: true},
+		{name: "too long", d: 65536 * time.Second, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := Options{PersistentKeepalive: tt.d}
+			if err := o.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			conf := Render(Peer{Addresses: []string{"10.0.0.2/32"}}, o)
+			if tt.want == "" {
+				if strings.Contains(conf, "PersistentKeepalive") {
+					t.Errorf("unexpected keepalive line:\n%s", conf)
+				}
+			} else if !strings.Contains(conf, tt.want) {
+				t.Errorf("config lacks %q:\n%s", tt.want, conf)
+			}
+		})
+	}
+}
+
+func TestMTU(t *testing.T) {
+	tests := []struct {
+		mtu     int
+		wantErr bool
+	}{
+		{mtu: 0},
+		{mtu: 1280},
+		{mtu: 1420},
+		{mtu: 9000},
+		{mtu: 1279, wantErr: true},
+		{mtu: 9001, wantErr: true},
+		{mtu: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		o := Options{MTU: tt.mtu}
+		if err := o.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("MTU %d: Validate() = %v, wantErr %v", tt.mtu, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		iface, _, _ := strings.Cut(Render(Peer{Addresses: []string{"10.0.0.2/32"}}, o), "[Peer]")
+		want := fmt.Sprintf("MTU = %d\n", tt.mtu)
+		if got := strings.Contains(iface, want); got != (tt.mtu != 0) {
+			t.Errorf("MTU %d: [Interface] section:\n%s", tt.mtu, iface)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package wgconfig
+
+import (
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QROptions tunes a config rendered as a QR code. The transports expose it
+// as QROptions.
+type QROptions struct {
+	// Config tunes the config text itself.
+	Config Options
+
+	// Size is the width and height of the PNG in pixels. Zero means 512,
+	// which mobile cameras read from a screen without trouble.
+	Size int
+}
+
+// Validate reports the first option that would produce a broken code.
+func (o QROptions) Validate() error {
+	if o.Size < 0 {
+		return fmt.Errorf("QR size %d: want a pixel count", o.Size)
+	}
+	return o.Config.Validate()
+}
+
+// QRPNG encodes conf as a PNG QR code of size pixels square.
+func QRPNG(conf string, size int) ([]byte, error) {
+	if size == 0 {
+		size = 512
+	}
+	return qrcode.Encode(conf, qrcode.Medium, size)
+}
+
+// QRText encodes conf as a QR code drawn with block characters, two modules
+// per line, for printing to a terminal.
+func QRText(conf string) (string, error) {
+	q, err := qrcode.New(conf, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+	return q.ToSmallString(false), nil
+}
@@ -0,0 +1,63 @@
+package wgconfig
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/testdata/qrdecode"
+)
+
+func TestQRPNG(t *testing.T) {
+	conf := Render(Peer{Addresses: []string{"10.0.0.2/32"}, Endpoint: "vpn.example.com", Port: 51820}, Options{})
+	for _, tt := range []struct{ size, want int }{{0, 512}, {256, 256}} {
+		b, err := QRPNG(conf, tt.size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r := img.Bounds(); r.Dx() != tt.want || r.Dy() != tt.want {
+			t.Errorf("size %d: image is %v, want %dx%d", tt.size, r, tt.want, tt.want)
+		}
+		if got := decodeQR(t, b); got != conf {
+			t.Errorf("size %d: code decodes to %q, want %q", tt.size, got, conf)
+		}
+	}
+}
+
+// decodeQR reads back the text of a PNG QR code.
+func decodeQR(t *testing.T, b []byte) string {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("not a PNG: %v", err)
+	}
+	text, err := qrdecode.Decode(img)
+	if err != nil {
+		t.Fatalf("decoding QR code: %v", err)
+	}
+	return text
+}
+
+func TestQRText(t *testing.T) {
+	s, err := QRText("[Interface]\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.ContainsAny(s, "█▀▄") {
+		t.Errorf("no block characters in %q", s)
+	}
+}
+
+func TestQROptionsValidate(t *testing.T) {
+	if err := (QROptions{Size: -1}).Validate(); err == nil {
+		t.Error("no error for a negative size")
+	}
+	if err := (QROptions{Config: Options{MTU: 1}}).Validate(); err == nil {
+		t.Error("config options not validated")
+	}
+}
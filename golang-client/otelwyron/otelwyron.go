@@ -0,0 +1,50 @@
+// Package otelwyron traces Wyron API calls with OpenTelemetry. It is a
+// module of its own, so only programs requiring it depend on OpenTelemetry.
+package otelwyron
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/stats"
+
+	"github.com/wyronapp/wyron-public/golang-client/grpc"
+	"github.com/wyronapp/wyron-public/golang-client/rest"
+)
+
+// Middleware returns a REST middleware that wraps each request, its
+// re-logins and retries included, in a client span named after the
+// operation, e.g. "wyron.users.List", and propagates the trace context.
+// Add it to rest.Config.Middleware.
+func Middleware(opts ...otelhttp.Option) rest.Middleware {
+	opts = append([]otelhttp.Option{otelhttp.WithSpanNameFormatter(spanName)}, opts...)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return otelhttp.NewTransport(next, opts...)
+	}
+}
+
+func spanName(_ string, r *http.Request) string {
+	return "wyron." + rest.OperationName(r.Method, r.URL.Path)
+}
+
+// StatsHandler returns a gRPC stats handler that wraps each RPC in a client
+// span named like Middleware's, e.g. "wyron.users.List", and propagates the
+// trace context. Add it to grpc.Config.StatsHandlers.
+func StatsHandler(opts ...otelgrpc.Option) stats.Handler {
+	return namedHandler{otelgrpc.NewClientHandler(opts...)}
+}
+
+// namedHandler renames otelgrpc's spans, which it names by full method and
+// gives no option for.
+type namedHandler struct {
+	stats.Handler
+}
+
+func (h namedHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	ctx = h.Handler.TagRPC(ctx, info)
+	trace.SpanFromContext(ctx).SetName("wyron." + grpc.OperationName(info.FullMethodName))
+	return ctx
+}
@@ -0,0 +1,120 @@
+package otelwyron
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	pb "github.com/wyronapp/wyron-public/golang-client/grpc/proto"
+	"github.com/wyronapp/wyron-public/golang-client/rest"
+)
+
+func TestMiddlewareSpans(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": "tok"})
+			return
+		}
+		if r.Header.Get("Traceparent") == "" {
+			t.Errorf("%s: no trace context propagated", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer srv.Close()
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	mw := Middleware(
+		otelhttp.WithTracerProvider(tp),
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+	)
+	c, err := rest.NewClientFromConfig(rest.Config{
+		BaseURL:    srv.URL,
+		Username:   "u",
+		Password:   "p",
+		Middleware: []rest.Middleware{mw},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	checkSpan(t, spans[0], "wyron.auth.Login", attribute.String("http.request.method", "POST"))
+	checkSpan(t, spans[1], "wyron.servers.List",
+		attribute.String("http.request.method", "GET"),
+		attribute.Int("http.response.status_code", 200),
+	)
+}
+
+func TestStatsHandlerSpans(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	pb.RegisterServerServiceServer(gs, pb.UnimplementedServerServiceServer{})
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(StatsHandler(otelgrpc.WithTracerProvider(tp))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// the server implements nothing, which still makes a complete span
+	pb.NewServerServiceClient(conn).List(context.Background(), &emptypb.Empty{})
+
+	spans := rec.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	checkSpan(t, spans[0], "wyron.servers.List",
+		attribute.String("rpc.service", "server.ServerService"),
+		attribute.String("rpc.method", "List"),
+	)
+}
+
+func checkSpan(t *testing.T, s sdktrace.ReadOnlySpan, name string, want ...attribute.KeyValue) {
+	t.Helper()
+	if s.Name() != name {
+		t.Errorf("span name = %q, want %q", s.Name(), name)
+	}
+	if s.SpanKind() != trace.SpanKindClient {
+		t.Errorf("%s: kind = %v, want client", name, s.SpanKind())
+	}
+	attrs := attribute.NewSet(s.Attributes()...)
+	for _, kv := range want {
+		if got, ok := attrs.Value(kv.Key); !ok || got != kv.Value {
+			t.Errorf("%s: %s = %v, want %v", name, kv.Key, got.Emit(), kv.Value.Emit())
+		}
+	}
+}
@@ -1,13 +1,122 @@
 package rest
 
-func (c *Client) Me() (map[string]any, error) {
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/relogin"
+	"github.com/wyronapp/wyron-public/golang-client/internal/token"
+)
+
+// refreshIfExpiring logs in ahead of a request when the token for ctx is
+// about to expire, or missing because a tenant set through WithCredentials
+// hasn't logged in yet.
+func (c *Client) refreshIfExpiring(ctx context.Context) error {
+	if !c.needsLogin(ctx) {
+		return nil
+	}
+
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	// another request may have logged in while we waited
+	if !c.needsLogin(ctx) {
+		return nil
+	}
+	_, err := c.loginFull(ctx)
+	return err
+}
+
+func (c *Client) needsLogin(ctx context.Context) bool {
+	tok, exp := c.session(ctx)
+	return tok == "" || token.Expiring(exp, c.cfg.RefreshAhead)
+}
+
+// reloginAfter replaces the token used, which the server rejected. Requests
+// rejected together share one login: whoever comes second finds the token
+// already replaced and just retries with it.
+func (c *Client) reloginAfter(ctx context.Context, used string) error {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if tok, _ := c.session(ctx); tok != used {
+		return nil
+	}
+	if !c.relogins.Allow() {
+		return ErrAuthLoop
+	}
+	if _, err := c.loginFull(ctx); err != nil {
+		return err
+	}
+	return relogin.Wait(ctx, c.cfg.ReloginDelay)
+}
+
+func (c *Client) Me(ctx context.Context) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("GET", "/auth/me", nil, nil, &out)
+	err := c.requestJSON(ctx, "GET", c.authPath("/auth/me"), nil, nil, &out)
 	return out, err
 }
 
-func (c *Client) Logout() (map[string]any, error) {
+// IsTokenValid checks the current token against /auth/me without the
+// re-login that regular requests do, reporting false on a 401.
+func (c *Client) IsTokenValid(ctx context.Context) (bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+c.authPath("/auth/me"), nil)
+	if err != nil {
+		return false, err
+	}
+	c.authHeader(req)
+
+	resp, err := c.httpc.Do(req)
+	if err != nil {
+		return false, err
+	}
+	raw, err := c.readBody(resp)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return false, nil
+	case resp.StatusCode/100 != 2:
+		return false, newAPIError(http.MethodGet, req.URL.Path, resp.StatusCode, raw)
+	}
+	return true, nil
+}
+
+// Ping checks that the server is reachable and accepts the current token,
+// without logging in again. It fails with ErrUnavailable when the server
+// can't be reached or answers 5xx, and with ErrUnauthenticated when the
+// token is rejected.
+func (c *Client) Ping(ctx context.Context) error {
+	ok, err := c.IsTokenValid(ctx)
+	switch {
+	case err != nil:
+		if code := StatusCode(err); ctx.Err() == nil && (code == 0 || code >= 500) {
+			return fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		return err
+	case !ok:
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+func (c *Client) Logout(ctx context.Context) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("POST", "/auth/logout", nil, nil, &out)
+	err := c.requestJSON(ctx, "POST", c.authPath("/auth/logout"), nil, nil, &out)
 	return out, err
 }
+
+// ScopedClient would return a client acting as the given sub-admin. The Wyron
+// API exposes no token exchange, so it always fails with ErrUnsupported.
+func (c *Client) ScopedClient(subAdminUsername string) (*Client, error) {
+	return nil, fmt.Errorf("%w: token exchange for %q", ErrUnsupported, subAdminUsername)
+}
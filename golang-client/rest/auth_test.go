@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrentUnauthorizedLogsInOnce(t *testing.T) {
+	const n = 20
+
+	var logins atomic.Int32
+	var stale sync.WaitGroup
+	stale.Add(n)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			tok := fmt.Sprintf("tok-%d", logins.Add(1))
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": tok})
+			return
+		}
+		if r.Header.Get("Authorization") == "Bearer tok-1" {
+			// hold every request on the first token until all of them
+			// have arrived, so they are all rejected together
+			stale.Done()
+			stale.Wait()
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer srv.Close()
+
+	c, err := NewClientFromConfig(Config{BaseURL: srv.URL, Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for range n {
+		wg.Go(func() {
+			if _, err := c.ListServers(context.Background()); err != nil {
+				errs <- err
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	// one initial login plus exactly one re-login
+	if got := logins.Load(); got != 2 {
+		t.Fatalf("logins = %d, want 2", got)
+	}
+}
+
+func TestLoginRacesRequests(t *testing.T) {
+	var logins atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			tok := fmt.Sprintf("tok-%d", logins.Add(1))
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": tok})
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer tok-") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer srv.Close()
+
+	c, err := NewClientFromConfig(Config{BaseURL: srv.URL, Username: "u", Password: "p", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if err := c.Login(ctx); err != nil {
+				t.Error(err)
+			}
+		})
+		wg.Go(func() {
+			if _, err := c.ListServers(ctx); err != nil {
+				t.Error(err)
+			}
+		})
+		wg.Go(func() {
+			c.TokenExpiry()
+		})
+	}
+	wg.Wait()
+}
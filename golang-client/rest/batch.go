@@ -0,0 +1,224 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/batch"
+)
+
+// The *Batch methods run one call per user key on at most concurrency
+// workers, after a Preflight unless Config.SkipBatchPreflight is set. On ctx
+// cancellation they stop starting new calls and return what completed so far
+// along with the context error.
+
+func (c *Client) GetUsersBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]User, map[string]error, error) {
+	var mu sync.Mutex
+	users := make(map[string]User, len(userKeys))
+
+	results, err := c.runBatch(ctx, userKeys, concurrency, func(ctx context.Context, key string) error {
+		u, err := c.GetUser(ctx, key)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		users[key] = u
+		mu.Unlock()
+		return nil
+	})
+
+	errs := make(map[string]error)
+	for key, e := range results {
+		if e != nil {
+			errs[key] = e
+		}
+	}
+	return users, errs, err
+}
+
+func (c *Client) EnableUsersBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]error, error) {
+	return c.runBatch(ctx, userKeys, concurrency, func(ctx context.Context, key string) error {
+		_, err := c.EnableUser(ctx, key)
+		return err
+	})
+}
+
+func (c *Client) DisableUsersBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]error, error) {
+	return c.runBatch(ctx, userKeys, concurrency, func(ctx context.Context, key string) error {
+		_, err := c.DisableUser(ctx, key)
+		return err
+	})
+}
+
+func (c *Client) DeleteUsersBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]error, error) {
+	return c.runBatch(ctx, userKeys, concurrency, func(ctx context.Context, key string) error {
+		_, err := c.DeleteUser(ctx, key)
+		return err
+	})
+}
+
+func (c *Client) ResetUsageBatch(ctx context.Context, userKeys []string, concurrency int) (map[string]error, error) {
+	return c.runBatch(ctx, userKeys, concurrency, func(ctx context.Context, key string) error {
+		_, err := c.ResetUsage(ctx, key)
+		return err
+	})
+}
+
+// InterfaceUpdate is one change of UpdateInterfacesBatch. Nil fields keep
+// the interface's current value.
+type InterfaceUpdate struct {
+	ServerID    string
+	Interface   string
+	DisplayName *string
+	Endpoint    *string
+	DNS         *string
+}
+
+// merged returns iface with the fields u sets replaced.
+func (u InterfaceUpdate) merged(iface WireGuardInterface) WireGuardInterface {
+	if u.DisplayName != nil {
+		iface.DisplayName = *u.DisplayName
+	}
+	if u.Endpoint != nil {
+		iface.Endpoint = *u.Endpoint
+	}
+	if u.DNS != nil {
+		iface.DNS = *u.DNS
+	}
+	return iface
+}
+
+// UpdateInterfacesBatch applies updates like the other *Batch methods, the
+// results keyed by "serverID/interface". Each interface may appear once.
+// An update replaces every field of the interface, so each interface is read
+// first and the update merged into it; a change made elsewhere between the
+// read and the write is lost.
+func (c *Client) UpdateInterfacesBatch(ctx context.Context, updates []InterfaceUpdate, concurrency int) (map[string]error, error) {
+	byKey, keys, err := indexInterfaceUpdates(updates)
+	if err != nil {
+		return nil, err
+	}
+	return c.runBatch(ctx, keys, concurrency, func(ctx context.Context, key string) error {
+		u := byKey[key]
+		srv, err := c.getServer(ctx, u.ServerID)
+		if err != nil {
+			return err
+		}
+		i := slices.IndexFunc(srv.Interfaces, func(i WireGuardInterface) bool { return i.Name == u.Interface })
+		if i < 0 {
+			return fmt.Errorf("%w: %s on server %s", ErrInterfaceNotFound, u.Interface, u.ServerID)
+		}
+		iface := u.merged(srv.Interfaces[i])
+		// the API names the DNS field in upper case
+		_, err = c.UpdateInterface(ctx, u.ServerID, map[string]any{
+			"name":         iface.Name,
+			"display_name": iface.DisplayName,
+			"endpoint":     iface.Endpoint,
+			"DNS":          iface.DNS,
+		})
+		return err
+	})
+}
+
+func indexInterfaceUpdates(updates []InterfaceUpdate) (map[string]InterfaceUpdate, []string, error) {
+	byKey := make(map[string]InterfaceUpdate, len(updates))
+	keys := make([]string, 0, len(updates))
+	for _, u := range updates {
+		if u.ServerID == "" || u.Interface == "" {
+			return nil, nil, errors.New("interface update needs a server ID and interface name")
+		}
+		key := u.ServerID + "/" + u.Interface
+		if _, ok := byKey[key]; ok {
+			return nil, nil, fmt.Errorf("duplicate interface update for %s", key)
+		}
+		byKey[key] = u
+		keys = append(keys, key)
+	}
+	return byKey, keys, nil
+}
+
+// Preflight checks that the server is reachable and the token accepted,
+// logging in again when it is not, so a bulk run fails up front rather than
+// partway through.
+func (c *Client) Preflight(ctx context.Context) error {
+	ok, err := c.IsTokenValid(ctx)
+	if err != nil {
+		return fmt.Errorf("preflight: %w", err)
+	}
+	if ok {
+		return nil
+	}
+	if err := c.Login(ctx); err != nil {
+		return fmt.Errorf("preflight: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) runBatch(ctx context.Context, keys []string, concurrency int, fn func(ctx context.Context, key string) error) (map[string]error, error) {
+	if !c.cfg.SkipBatchPreflight {
+		if err := c.Preflight(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return batch.Run(ctx, keys, concurrency, fn)
+}
+
+// ValidateBatch checks a batch of creates before any is sent, as the server
+// has no dry-run mode: each entry's own Validate, duplicate user keys and
+// social IDs within the batch, and that every referenced server and interface
+// exists. The result is aligned with params, nil for entries that pass; err
+// is only set when the servers could not be listed.
+func (c *Client) ValidateBatch(ctx context.Context, params []CreateUserParams) ([]error, error) {
+	servers, err := c.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ifaces := make(map[string]map[string]bool, len(servers))
+	for _, s := range servers {
+		names := make(map[string]bool, len(s.Interfaces))
+		for _, i := range s.Interfaces {
+			names[i.Name] = true
+		}
+		ifaces[s.Name] = names
+	}
+
+	keys := make(map[string]int)
+	socialIDs := make(map[int64]int)
+	out := make([]error, len(params))
+	for n, p := range params {
+		errs := p.Validate()
+		if p.UserKey != "" {
+			if first, ok := keys[p.UserKey]; ok {
+				errs = append(errs, FieldError{FieldUserKey, fmt.Sprintf("duplicate of entry %d", first)})
+			} else {
+				keys[p.UserKey] = n
+			}
+		}
+		if p.SocialID > 0 {
+			if first, ok := socialIDs[p.SocialID]; ok {
+				errs = append(errs, FieldError{FieldSocialID, fmt.Sprintf("duplicate of entry %d", first)})
+			} else {
+				socialIDs[p.SocialID] = n
+			}
+		}
+		for i, a := range p.ServerAccess {
+			names, ok := ifaces[a.ServerID]
+			if !ok {
+				if a.ServerID != "" {
+					errs = append(errs, FieldError{FieldServerAccess, fmt.Sprintf("entry %d: unknown server %q", i, a.ServerID)})
+				}
+				continue
+			}
+			for _, name := range a.Interfaces {
+				if name != "" && !names[name] {
+					errs = append(errs, FieldError{FieldServerAccess, fmt.Sprintf("entry %d: unknown interface %q on %s", i, name, a.ServerID)})
+				}
+			}
+		}
+		out[n] = validationError(errs)
+	}
+	return out, nil
+}
@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestUpdateInterfacesBatchMerges(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodGet, "/api/auth/me", resttest.JSON(http.StatusOK, map[string]any{"ok": true}))
+	srv.Handle(http.MethodGet, "/api/servers/srv", resttest.JSON(http.StatusOK, map[string]any{
+		"data": Server{Name: "srv", Interfaces: []WireGuardInterface{
+			{Name: "wg0", DisplayName: "Main", Endpoint: "vpn.example.com", DNS: "1.1.1.1"},
+		}},
+	}))
+	srv.Handle(http.MethodPost, "/api/servers/srv/interfaces", resttest.JSON(http.StatusOK, map[string]any{"ok": true}))
+
+	dns := "9.9.9.9"
+	errs, err := c.UpdateInterfacesBatch(context.Background(), []InterfaceUpdate{
+		{ServerID: "srv", Interface: "wg0", DNS: &dns},
+		{ServerID: "srv", Interface: "wg9", DNS: &dns},
+	}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := errs["srv/wg0"]; err != nil {
+		t.Errorf("srv/wg0: %v", err)
+	}
+	if err := errs["srv/wg9"]; !errors.Is(err, ErrInterfaceNotFound) {
+		t.Errorf("srv/wg9: err = %v, want ErrInterfaceNotFound", err)
+	}
+
+	var sent []map[string]any
+	for _, r := range srv.Requests() {
+		if r.Method != http.MethodPost {
+			continue
+		}
+		var body map[string]any
+		if err := json.Unmarshal(r.Body, &body); err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, body)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("sent %d updates, want 1", len(sent))
+	}
+	want := map[string]any{"name": "wg0", "display_name": "Main", "endpoint": "vpn.example.com", "DNS": dns}
+	for k, v := range want {
+		if sent[0][k] != v {
+			t.Errorf("sent %s = %v, want %v", k, sent[0][k], v)
+		}
+	}
+}
+
+func TestGetUsersBatchPartial(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodGet, "/api/auth/me", resttest.JSON(http.StatusOK, map[string]any{"ok": true}))
+	srv.Handle(http.MethodGet, "/api/users/a", resttest.JSON(http.StatusOK, map[string]any{"result": User{UserKey: "a"}}))
+	srv.Handle(http.MethodGet, "/api/users/c", resttest.JSON(http.StatusOK, map[string]any{"result": User{UserKey: "c"}}))
+
+	users, errs, err := c.GetUsersBatch(context.Background(), []string{"a", "b", "c"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users["a"].UserKey != "a" || users["c"].UserKey != "c" {
+		t.Errorf("users = %v, want a and c", users)
+	}
+	if len(errs) != 1 || StatusCode(errs["b"]) != http.StatusNotFound {
+		t.Errorf("errs = %v, want a 404 for b", errs)
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodGet, "/api/servers", resttest.JSON(http.StatusOK, map[string]any{
+		"data": []Server{{Name: "srv", Interfaces: []WireGuardInterface{{Name: "wg0"}}}},
+	}))
+
+	access := func(server, iface string) []AccessRequest {
+		return []AccessRequest{{ServerID: server, Interfaces: []string{iface}}}
+	}
+	params := []CreateUserParams{
+		{UserKey: "a", SocialID: 1, ServerAccess: access("srv", "wg0")},
+		{UserKey: "a", SocialID: 2},
+		{UserKey: "c", SocialID: 1},
+		{UserKey: "d", SocialID: 4, ServerAccess: access("gone", "wg0")},
+		{UserKey: "e", SocialID: 5, ServerAccess: access("srv", "wg9")},
+		{UserKey: "f", SocialID: 6},
+	}
+	// the field each entry fails on, "" for entries that pass
+	want := []string{"", FieldUserKey, FieldSocialID, FieldServerAccess, FieldServerAccess, ""}
+
+	errs, err := c.ValidateBatch(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != len(params) {
+		t.Fatalf("got %d results for %d entries", len(errs), len(params))
+	}
+	for n, field := range want {
+		if field == "" {
+			if errs[n] != nil {
+				t.Errorf("entry %d: unexpected %v", n, errs[n])
+			}
+			continue
+		}
+		var ve *ValidationError
+		if !errors.As(errs[n], &ve) || len(ve.Errors) != 1 || ve.Errors[0].Field != field {
+			t.Errorf("entry %d: err = %v, want one error on %s", n, errs[n], field)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package rest
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,45 +12,305 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/wyronapp/wyron-public/golang-client/internal/creds"
+	"github.com/wyronapp/wyron-public/golang-client/internal/dialer"
+	"github.com/wyronapp/wyron-public/golang-client/internal/op"
+	"github.com/wyronapp/wyron-public/golang-client/internal/ratelimit"
+	"github.com/wyronapp/wyron-public/golang-client/internal/relogin"
+	"github.com/wyronapp/wyron-public/golang-client/internal/swr"
+	"github.com/wyronapp/wyron-public/golang-client/internal/tlsconf"
+	"github.com/wyronapp/wyron-public/golang-client/internal/token"
+	"github.com/wyronapp/wyron-public/golang-client/internal/useragent"
+	"golang.org/x/net/http/httpguts"
 	"golang.org/x/net/proxy"
 )
 
+type LoginContentType string
+
+const (
+	LoginContentJSON LoginContentType = "json"
+	LoginContentForm LoginContentType = "form"
+)
+
+type Config struct {
+	BaseURL  string
+	Username string
+	Password string
+	ProxyURL string
+	Timeout  time.Duration
+
+	// DialTimeout bounds the TCP connect only; Timeout still bounds the whole
+	// request. Defaults to Timeout.
+	DialTimeout time.Duration
+
+	// Resolver replaces the system resolver for the backend connection, and
+	// StaticHosts pins hosts to fixed IPs so they are never looked up at all.
+	// Behind an HTTP proxy only the proxy host is affected, as the proxy
+	// resolves the backend itself.
+	Resolver    *net.Resolver
+	StaticHosts map[string]string
+
+	// MinTLSVersion and CipherSuites restrict the TLS handshake, e.g. to
+	// tls.VersionTLS13 only. The version must be at least TLS 1.2, and cipher
+	// suites only apply up to TLS 1.2 as Go doesn't make TLS 1.3 ones
+	// configurable.
+	MinTLSVersion uint16
+	CipherSuites  []uint16
+
+	// TLSConfig is the base of the backend's TLS config, e.g. for client
+	// certificates; it is cloned, with the fields above and below applied on
+	// top. RootCAs trusts a private CA instead of the system roots, and
+	// InsecureSkipVerify turns certificate checks off altogether, for
+	// development against self-signed backends only. The same settings
+	// verify an https:// proxy; a SOCKS5 or http:// proxy is unaffected.
+	TLSConfig          *tls.Config
+	RootCAs            *x509.CertPool
+	InsecureSkipVerify bool
+
+	// BasePath is the prefix every resource is served under, "/api" by default.
+	// The per-resource paths below fall back to it when empty, which lets a
+	// client talk to a backend that versions resources independently.
+	BasePath        string
+	AuthBasePath    string
+	UsersBasePath   string
+	ServersBasePath string
+
+	// AuthHeaderName and AuthScheme shape how the token is sent, by default
+	// "Authorization: Bearer <token>". With a custom header name and no
+	// scheme the bare token is sent.
+	AuthHeaderName string
+	AuthScheme     string
+
+	// UserAgent identifies the client in server logs, by default
+	// "wyron-go-client/<version>".
+	UserAgent string
+
+	// Headers are sent with every request, logins included, e.g. an API key
+	// a gateway requires. They never replace a header the client sets
+	// itself, and may not name the auth header or User-Agent.
+	Headers map[string]string
+
+	// LoginContentType selects how credentials are encoded for the login call
+	// only; every other request is JSON. Defaults to LoginContentJSON.
+	LoginContentType LoginContentType
+
+	// ReloginDelay is waited after a re-login triggered by a rejected token,
+	// before the request is retried. At most MaxRelogins (5 by default) such
+	// re-logins may happen per ReloginWindow (a minute by default); beyond
+	// that requests fail with ErrAuthLoop instead of hammering the server.
+	ReloginDelay  time.Duration
+	MaxRelogins   int
+	ReloginWindow time.Duration
+
+	// FatalAuthCodes lists the reasons a 401 can carry that a re-login won't
+	// fix, such as "account_disabled"; such a 401 fails with
+	// ErrAccountDisabled instead. The reason is read from the AuthErrorHeader
+	// header when set, else from the AuthErrorField field ("code" by default)
+	// of a JSON body, and compared case-insensitively. Without any codes
+	// every 401 triggers a re-login.
+	FatalAuthCodes  []string
+	AuthErrorHeader string
+	AuthErrorField  string
+
+	// SkipBatchPreflight stops the *Batch methods from calling Preflight
+	// before their first call.
+	SkipBatchPreflight bool
+
+	// MaxLoginRetries retries the initial login in NewClientFromConfig while
+	// the backend can't be reached or answers 5xx, waiting LoginRetryBackoff
+	// (500ms by default) and doubling it up to 10s between attempts.
+	// Rejected credentials fail at once.
+	MaxLoginRetries   int
+	LoginRetryBackoff time.Duration
+
+	// RefreshAhead re-logins before a request once the token expires within
+	// this window. Zero keeps the reactive re-login on 401 only.
+	RefreshAhead time.Duration
+
+	Middleware []Middleware
+
+	// MinServerVersion makes construction fail with ErrIncompatibleServer
+	// when the backend reports an older version, and with
+	// ErrUnknownVersion when it reports none, as the current API
+	// defines no version endpoint. Empty skips the check and its extra
+	// round-trip.
+	MinServerVersion string
+
+	// ReadCacheFresh and ReadCacheStale enable a stale-while-revalidate cache
+	// for ListServers and GetServer: results are reused for ReadCacheFresh,
+	// then served stale for up to ReadCacheStale more while a background
+	// refresh runs. Server writes through this client purge the cache; changes
+	// made elsewhere show up after at most ReadCacheFresh+ReadCacheStale.
+	// Entries are kept per credential set (see WithCredentials), so tenants
+	// never see each other's results. Cached values are shared between
+	// callers and must not be modified.
+	ReadCacheFresh time.Duration
+	ReadCacheStale time.Duration
+
+	// MaxResponseBytes caps how much of a response body is read before the
+	// request fails with ErrResponseTooLarge. Defaults to 32MB.
+	MaxResponseBytes int64
+
+	// RateLimit caps outgoing requests to this many per second across all
+	// goroutines, allowing bursts of up to RateBurst (1 by default). Logins,
+	// re-login retries and batch calls all count, and waiting honors the
+	// request's context. Zero leaves requests unlimited.
+	RateLimit float64
+	RateBurst int
+
+	// RequestLogger, when set, is called after every API request with its
+	// method, path, status (0 when no response arrived) and duration,
+	// failures included. Re-logins and retries happen within one call.
+	// Nothing carrying credentials or tokens is passed to it.
+	RequestLogger func(method, path string, status int, took time.Duration)
+
+	// MetricsObserver, when set, is called once per API request with the
+	// operation, e.g. "users.List", its duration and its final error, after
+	// any re-login and retries.
+	MetricsObserver func(op string, took time.Duration, err error)
+
+	// Retry retries requests that failed to connect or got a 502, 503 or 504,
+	// within the request's deadline. Only GET, HEAD, OPTIONS, PUT and DELETE
+	// are retried unless Retry.NonIdempotent is set, as a POST may have
+	// taken effect before failing. The zero policy never retries.
+	Retry RetryPolicy
+}
+
+const defaultMaxResponseBytes = 32 << 20
+
 type Client struct {
+	cfg Config
+
 	baseURL  string
 	username string
 	password string
+
+	mu       sync.RWMutex
 	token    string
+	tokenExp time.Time
 
-	httpc   *http.Client
-	timeout time.Duration
+	loginMu sync.Mutex
+
+	// tenants holds the tokens of credentials set through WithCredentials.
+	tenants creds.Store
+
+	transport *http.Transport
+	httpc     *http.Client
+	api       *http.Client
+	timeout   time.Duration
+
+	relogins *relogin.Limiter
+	limiter  *ratelimit.Limiter
+
+	serverList *swr.Cache[[]Server]
+	serverByID *swr.Cache[Server]
 }
 
 func NewClient(baseURL, username, password, proxyURL string, timeout time.Duration) (*Client, error) {
-	if baseURL == "" || username == "" || password == "" {
+	return NewClientFromConfig(Config{
+		BaseURL:  baseURL,
+		Username: username,
+		Password: password,
+		ProxyURL: proxyURL,
+		Timeout:  timeout,
+	})
+}
+
+func NewClientFromConfig(cfg Config) (*Client, error) {
+	if cfg.BaseURL == "" || cfg.Username == "" || cfg.Password == "" {
 		return nil, errors.New("baseURL/username/password required")
 	}
-	if timeout <= 0 {
-		timeout = 15 * time.Second
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 15 * time.Second
+	}
+	if cfg.MaxRelogins <= 0 {
+		cfg.MaxRelogins = 5
+	}
+	if cfg.ReloginWindow <= 0 {
+		cfg.ReloginWindow = time.Minute
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = cfg.Timeout
+	}
+	if cfg.AuthErrorField == "" {
+		cfg.AuthErrorField = "code"
+	}
+	if cfg.LoginRetryBackoff <= 0 {
+		cfg.LoginRetryBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxResponseBytes <= 0 {
+		cfg.MaxResponseBytes = defaultMaxResponseBytes
+	}
+	if err := cfg.normalizePaths(); err != nil {
+		return nil, err
+	}
+	if err := dialer.ValidateHosts(cfg.StaticHosts); err != nil {
+		return nil, err
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = useragent.Default()
+	}
+	var err error
+	if cfg.AuthHeaderName, cfg.AuthScheme, err = token.Header(cfg.AuthHeaderName, cfg.AuthScheme); err != nil {
+		return nil, err
+	}
+	if err := validateHeaders(cfg.Headers, cfg.AuthHeaderName); err != nil {
+		return nil, err
+	}
+	var minVersion []int
+	if cfg.MinServerVersion != "" {
+		if minVersion, err = parseVersion(cfg.MinServerVersion); err != nil {
+			return nil, fmt.Errorf("invalid MinServerVersion: %w", err)
+		}
+	}
+	switch cfg.LoginContentType {
+	case "":
+		cfg.LoginContentType = LoginContentJSON
+	case LoginContentJSON, LoginContentForm:
+	default:
+		return nil, fmt.Errorf("invalid LoginContentType %q", cfg.LoginContentType)
+	}
+	timeout := cfg.Timeout
+
+	d := &dialer.Dialer{
+		Dialer: &net.Dialer{
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: 30 * time.Second,
+			Resolver:  cfg.Resolver,
+		},
+		Hosts: cfg.StaticHosts,
 	}
 
 	// http2: Go by default tries HTTP/2 over TLS; for h2c you’d need extra setup.
 	tr := &http.Transport{
 		ForceAttemptHTTP2: true,
-		DialContext: (&net.Dialer{
-			Timeout:   timeout,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		DialContext:       d.DialContext,
 
 		TLSHandshakeTimeout:   10 * time.Second,
 		ResponseHeaderTimeout: timeout,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
-	if proxyURL != "" {
-		u, err := url.Parse(proxyURL)
+	if tr.TLSClientConfig, err = tlsconf.Build(cfg.MinTLSVersion, cfg.CipherSuites); err != nil {
+		return nil, err
+	}
+	if cfg.TLSConfig != nil || cfg.RootCAs != nil || cfg.InsecureSkipVerify {
+		tr.TLSClientConfig = tlsconf.Over(cfg.TLSConfig, tr.TLSClientConfig)
+		if cfg.RootCAs != nil {
+			tr.TLSClientConfig.RootCAs = cfg.RootCAs
+		}
+		if cfg.InsecureSkipVerify {
+			tr.TLSClientConfig.InsecureSkipVerify = true
+		}
+	}
+
+	if cfg.ProxyURL != "" {
+		u, err := url.Parse(cfg.ProxyURL)
 		if err != nil {
 			return nil, err
 		}
@@ -56,144 +318,387 @@ func NewClient(baseURL, username, password, proxyURL string, timeout time.Durati
 		if u.Scheme == "http" || u.Scheme == "https" {
 			tr.Proxy = http.ProxyURL(u)
 		} else {
-			dialer, err := proxy.FromURL(u, proxy.Direct)
+			pd, err := proxy.FromURL(u, d)
 			if err != nil {
 				return nil, err
 			}
 			tr.Proxy = nil
 			tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
-				return dialer.Dial(network, addr)
+				return pd.Dial(network, d.Rewrite(addr))
 			}
 		}
 	}
 
 	c := &Client{
-		baseURL:  strings.TrimRight(baseURL, "/") + "/api",
-		username: username,
-		password: password,
+		cfg:      cfg,
+		baseURL:  strings.TrimRight(cfg.BaseURL, "/"),
+		username: cfg.Username,
+		password: cfg.Password,
 		timeout:  timeout,
-		httpc: &http.Client{
-			Transport: tr,
-			Timeout:   timeout,
-		},
+
+		transport: tr,
+
+		relogins: relogin.NewLimiter(cfg.MaxRelogins, cfg.ReloginWindow),
+		limiter:  ratelimit.New(cfg.RateLimit, cfg.RateBurst),
+
+		serverList: swr.New[[]Server](cfg.ReadCacheFresh, cfg.ReadCacheStale),
+		serverByID: swr.New[Server](cfg.ReadCacheFresh, cfg.ReadCacheStale),
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
+	// the limiter sits innermost so retries made by the layers above count too
+	base := c.headerMiddleware(c.limitMiddleware(tr))
+	mws := append([]Middleware{}, cfg.Middleware...)
+	// no client-wide Timeout: requests take the caller's deadline, falling
+	// back to Timeout only when there is none
+	c.httpc = &http.Client{
+		Transport: chain(base, mws...),
+	}
+	c.api = &http.Client{
+		Transport: chain(base, append(mws, c.reloginMiddleware, c.authMiddleware, c.retryMiddleware)...),
+	}
 
-	if err := c.Login(ctx); err != nil {
+	if err := c.initialLogin(context.Background()); err != nil {
 		return nil, err
 	}
+	if minVersion != nil {
+		if err := c.checkServerVersion(context.Background(), minVersion); err != nil {
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
+const maxLoginBackoff = 10 * time.Second
+
+// initialLogin is Login retried up to MaxLoginRetries times while the
+// backend is not ready yet, each attempt bounded by Timeout.
+func (c *Client) initialLogin(ctx context.Context) error {
+	backoff := c.cfg.LoginRetryBackoff
+	for attempt := 0; ; attempt++ {
+		actx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := c.Login(actx)
+		cancel()
+		if err == nil || attempt >= c.cfg.MaxLoginRetries ||
+			errors.Is(err, ErrAuthFailed) || errors.Is(err, ErrLoginNoToken) || errors.Is(err, ErrResponseTooLarge) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxLoginBackoff)
+	}
+}
+
+// Close drops the client's idle connections. Requests after Close still
+// work, opening new ones.
+func (c *Client) Close() error {
+	c.transport.CloseIdleConnections()
+	return nil
+}
+
+func validateHeaders(h map[string]string, authHeader string) error {
+	for k, v := range h {
+		switch {
+		case !httpguts.ValidHeaderFieldName(k):
+			return fmt.Errorf("header %q: invalid name", k)
+		case !httpguts.ValidHeaderFieldValue(v):
+			return fmt.Errorf("header %s: invalid value", k)
+		case strings.EqualFold(k, authHeader), strings.EqualFold(k, "User-Agent"):
+			return fmt.Errorf("header %s: set through its own option", k)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) normalizePaths() error {
+	var err error
+	if cfg.BasePath, err = normalizeBasePath("BasePath", cfg.BasePath, "/api"); err != nil {
+		return err
+	}
+	if cfg.AuthBasePath, err = normalizeBasePath("AuthBasePath", cfg.AuthBasePath, cfg.BasePath); err != nil {
+		return err
+	}
+	if cfg.UsersBasePath, err = normalizeBasePath("UsersBasePath", cfg.UsersBasePath, cfg.BasePath); err != nil {
+		return err
+	}
+	if cfg.ServersBasePath, err = normalizeBasePath("ServersBasePath", cfg.ServersBasePath, cfg.BasePath); err != nil {
+		return err
+	}
+	return nil
+}
+
+// normalizeBasePath returns p as "/seg/seg" without a trailing slash; "/"
+// maps to the server root and "" to def.
+func normalizeBasePath(name, p, def string) (string, error) {
+	if p == "" {
+		return def, nil
+	}
+	if strings.ContainsAny(p, "?#") || strings.Contains(p, "://") {
+		return "", fmt.Errorf("invalid %s %q: must be a plain path", name, p)
+	}
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return "", nil
+	}
+	return p, nil
+}
+
+func (c *Client) authPath(p string) string {
+	return c.cfg.AuthBasePath + p
+}
+
+func (c *Client) usersPath(p string) string {
+	return c.cfg.UsersBasePath + p
+}
+
+func (c *Client) serversPath(p string) string {
+	return c.cfg.ServersBasePath + p
+}
+
 func (c *Client) authHeader(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
+	if tok, _ := c.session(req.Context()); tok != "" {
+		req.Header.Set(c.cfg.AuthHeaderName, token.Value(c.cfg.AuthScheme, tok))
 	}
 }
 
+// Login fetches a token for the configured credentials, or for those set on
+// ctx with WithCredentials.
 func (c *Client) Login(ctx context.Context) error {
-	body := map[string]any{
-		"username": c.username,
-		"password": c.password,
+	_, err := c.LoginFull(ctx)
+	return err
+}
+
+// LoginResult is the decoded login response. Gateways in front of the API
+// may add fields such as roles or a tenant; those are left in Extra.
+type LoginResult struct {
+	Token        string
+	RefreshToken string
+
+	// ExpiresAt is the expiry TokenExpiry reports afterwards: expires_in or
+	// exp from the response, else the token's exp claim.
+	ExpiresAt time.Time
+
+	Extra map[string]any
+}
+
+// LoginFull is Login that also returns the whole login response.
+func (c *Client) LoginFull(ctx context.Context) (*LoginResult, error) {
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	return c.loginFull(ctx)
+}
+
+func (c *Client) loginFull(ctx context.Context) (*LoginResult, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	username, password := c.credentials(ctx)
+	var (
+		b           []byte
+		contentType string
+	)
+	switch c.cfg.LoginContentType {
+	case LoginContentForm:
+		form := url.Values{}
+		form.Set("username", username)
+		form.Set("password", password)
+		b = []byte(form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	default:
+		body := map[string]any{
+			"username": username,
+			"password": password,
+		}
+		b, _ = json.Marshal(body)
+		contentType = "application/json"
 	}
-	b, _ := json.Marshal(body)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/auth/login", bytes.NewReader(b))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+c.authPath("/auth/login"), bytes.NewReader(b))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 
 	resp, err := c.httpc.Do(req)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	raw, err := c.readBody(resp)
+	if err != nil {
+		return nil, err
 	}
-	defer func(Body io.ReadCloser) {
-		_ = Body.Close()
-	}(resp.Body)
 
+	if resp.StatusCode >= 500 {
+		return nil, fmt.Errorf("%w: login status=%d body=%s", ErrUnavailable, resp.StatusCode, string(raw))
+	}
 	if resp.StatusCode/100 != 2 {
-		raw, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("login failed: status=%d body=%s", resp.StatusCode, string(raw))
+		return nil, fmt.Errorf("%w: status=%d body=%s", ErrAuthFailed, resp.StatusCode, string(raw))
 	}
 
 	var out struct {
-		Token string `json:"token"`
+		Token     string `json:"token"`
+		ExpiresIn int64  `json:"expires_in"`
+		Exp       int64  `json:"exp"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return err
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("%w: malformed body=%s: %v", ErrLoginNoToken, string(raw), err)
 	}
 	if out.Token == "" {
-		return errors.New("login failed: token missing")
+		return nil, fmt.Errorf("%w: body=%s", ErrLoginNoToken, string(raw))
 	}
-	c.token = out.Token
-	return nil
+
+	exp := token.Expiry(out.Token)
+	switch {
+	case out.ExpiresIn > 0:
+		exp = time.Now().Add(time.Duration(out.ExpiresIn) * time.Second)
+	case out.Exp > 0:
+		exp = time.Unix(out.Exp, 0)
+	}
+
+	c.setSession(ctx, out.Token, exp)
+
+	res := &LoginResult{Token: out.Token, ExpiresAt: exp}
+	if err := json.Unmarshal(raw, &res.Extra); err == nil {
+		res.RefreshToken, _ = res.Extra["refresh_token"].(string)
+		for _, k := range []string{"token", "refresh_token", "expires_in", "exp"} {
+			delete(res.Extra, k)
+		}
+	}
+	return res, nil
+}
+
+func (c *Client) getToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+func (c *Client) setToken(tok string, exp time.Time) {
+	c.mu.Lock()
+	c.token = tok
+	c.tokenExp = exp
+	c.mu.Unlock()
 }
 
-func (c *Client) requestJSON(method, path string, query url.Values, payload any, out any) error {
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
-	defer cancel()
+// TokenExpiry returns when the current token expires, or the zero time when
+// the login response carried no expiry.
+func (c *Client) TokenExpiry() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tokenExp
+}
+
+// ParseTokenClaims returns the claims of the current token, such as exp, sub
+// or roles. The signature is not verified, so the claims are only fit for
+// display and scheduling, not for trust decisions.
+func (c *Client) ParseTokenClaims() (map[string]any, error) {
+	claims, err := token.Claims(c.getToken())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTokenNotJWT, err)
+	}
+	return claims, nil
+}
 
+func (c *Client) requestJSON(ctx context.Context, method, path string, query url.Values, payload any, out any) error {
+	_, err := c.do(ctx, method, path, query, payload, out)
+	return err
+}
+
+// do is requestJSON that also hands back the response, its body already
+// consumed, so callers can inspect the status and headers; on a non-2xx
+// status both the response and an error are returned.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, payload any, out any) (*http.Response, error) {
 	full := c.baseURL + path
 	if query != nil && len(query) > 0 {
 		full += "?" + query.Encode()
 	}
+	return c.doURL(ctx, method, full, payload, out)
+}
+
+// doURL sends the request with ctx and reports it to MetricsObserver.
+func (c *Client) doURL(ctx context.Context, method, full string, payload any, out any) (*http.Response, error) {
+	obs := c.cfg.MetricsObserver
+	if obs == nil {
+		return c.send(ctx, method, full, payload, out)
+	}
+	start := time.Now()
+	resp, err := c.send(ctx, method, full, payload, out)
+	name := method + " " + full
+	if u, perr := url.Parse(full); perr == nil {
+		name = op.FromHTTP(method, u.Path)
+	}
+	obs(name, time.Since(start), err)
+	return resp, err
+}
+
+// send sends the request with ctx, applying Timeout when ctx carries no
+// deadline of its own.
+func (c *Client) send(ctx context.Context, method, full string, payload any, out any) (*http.Response, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
 
 	var body io.Reader
 	if payload != nil {
 		b, err := json.Marshal(payload)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		body = bytes.NewReader(b)
 	}
 
-	doOnce := func() (*http.Response, []byte, error) {
-		req, err := http.NewRequestWithContext(ctx, method, full, body)
-		if err != nil {
-			return nil, nil, err
-		}
-		c.authHeader(req)
+	req, err := http.NewRequestWithContext(ctx, method, full, body)
+	if err != nil {
+		return nil, err
+	}
 
-		resp, err := c.httpc.Do(req)
-		if err != nil {
-			return nil, nil, err
+	start := time.Now()
+	resp, err := c.api.Do(req)
+	if c.cfg.RequestLogger != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
 		}
-		raw, err := io.ReadAll(resp.Body)
-		_ = resp.Body.Close()
-		return resp, raw, err
+		c.cfg.RequestLogger(method, req.URL.Path, status, time.Since(start))
 	}
-
-	resp, raw, err := doOnce()
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// auto re-login on 401
-	if resp.StatusCode == http.StatusUnauthorized {
-		if err := c.Login(ctx); err != nil {
-			return err
-		}
-		// reset body reader for retry (اگر payload داشت)
-		if payload != nil {
-			b, _ := json.Marshal(payload)
-			body = bytes.NewReader(b)
-		}
-		resp, raw, err = doOnce()
-		if err != nil {
-			return err
-		}
+	raw, err := c.readBody(resp)
+	if err != nil {
+		return nil, err
 	}
 
 	if resp.StatusCode/100 != 2 {
-		return fmt.Errorf("api error: %s %s status=%d body=%s", method, path, resp.StatusCode, string(raw))
+		return resp, newAPIError(method, req.URL.Path, resp.StatusCode, raw)
 	}
 
-	if out == nil {
-		return nil
+	if out == nil || len(raw) == 0 {
+		return resp, nil
+	}
+	return resp, json.Unmarshal(raw, out)
+}
+
+// readBody reads and closes resp.Body, failing once it exceeds
+// MaxResponseBytes rather than buffering an unbounded body.
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	limit := c.cfg.MaxResponseBytes
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(raw)) > limit {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrResponseTooLarge, limit)
 	}
-	return json.Unmarshal(raw, out)
+	return raw, nil
 }
@@ -0,0 +1,16 @@
+package rest
+
+import "time"
+
+// now is the clock behind the expiry and quota helpers.
+var now = time.Now
+
+// SetClock replaces the clock used by ExpiresAt-based helpers such as
+// DaysUntilExpiry, IsExpired and Summary, so tests can freeze time. nil
+// restores time.Now. It is not safe to call concurrently with those helpers.
+func SetClock(fn func() time.Time) {
+	if fn == nil {
+		fn = time.Now
+	}
+	now = fn
+}
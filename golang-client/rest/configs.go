@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// GeneratedConfig is one rendered peer config, labeled with its owner and
+// a FileName unique within the call that produced it. Label names the
+// server and interface for display, preferring their display names.
+type GeneratedConfig struct {
+	UserKey   string
+	SocialID  int64
+	ServerID  string
+	Interface string
+	FileName  string
+	Label     string
+	Config    string
+}
+
+// serverResolver memoizes GetServer for the duration of one bulk operation,
+// failures included, so users sharing a server cost a single fetch.
+type serverResolver struct {
+	c       *Client
+	servers map[string]*Server
+	errs    map[string]error
+}
+
+func (c *Client) newServerResolver() *serverResolver {
+	return &serverResolver{c: c, servers: make(map[string]*Server), errs: make(map[string]error)}
+}
+
+func (r *serverResolver) get(ctx context.Context, id string) (*Server, error) {
+	if s, ok := r.servers[id]; ok {
+		return s, nil
+	}
+	if err, ok := r.errs[id]; ok {
+		return nil, err
+	}
+	s, err := r.c.GetServer(ctx, id)
+	if err != nil {
+		r.errs[id] = err
+		return nil, err
+	}
+	r.servers[id] = &s
+	return &s, nil
+}
+
+// GenerateUserConfigs renders every peer of user. Peers that fail are
+// skipped and reported in the returned error.
+func (c *Client) GenerateUserConfigs(ctx context.Context, user User, opts ConfigOptions) ([]GeneratedConfig, error) {
+	return c.userConfigs(ctx, c.newServerResolver(), make(map[string]int), user, opts)
+}
+
+// GenerateConfigsForSocialID renders every peer of every user with the given
+// social ID. Users or peers that fail are skipped and reported in the
+// returned error.
+func (c *Client) GenerateConfigsForSocialID(ctx context.Context, socialID int64, opts ConfigOptions) ([]GeneratedConfig, error) {
+	var users []User
+	it := c.UsersIterator(ListUsersOptions{SocialID: &socialID, Limit: maxPageSize, SortBy: SortByCreatedAt, OrderBy: OrderAsc})
+	for {
+		u, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		users = append(users, *u)
+	}
+
+	r := c.newServerResolver()
+	used := make(map[string]int)
+	var out []GeneratedConfig
+	var errs []error
+	for _, u := range users {
+		confs, err := c.userConfigs(ctx, r, used, u, opts)
+		out = append(out, confs...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+func (c *Client) userConfigs(ctx context.Context, r *serverResolver, used map[string]int, user User, opts ConfigOptions) ([]GeneratedConfig, error) {
+	if len(user.Peers) == 0 {
+		return nil, fmt.Errorf("user %s: %w", user.UserKey, ErrUserNoPeers)
+	}
+
+	var out []GeneratedConfig
+	var errs []error
+	for _, p := range user.Peers {
+		var conf string
+		srv, err := r.get(ctx, p.ServerID)
+		if err == nil {
+			conf, err = p.GenerateConfigWithOptions(srv, opts)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s peer %s/%s: %w", user.UserKey, p.ServerID, p.Interface, err))
+			continue
+		}
+		out = append(out, GeneratedConfig{
+			UserKey:   user.UserKey,
+			SocialID:  user.SocialID,
+			ServerID:  p.ServerID,
+			Interface: p.Interface,
+			FileName:  archiveName(used, fmt.Sprintf("%d-%s", user.SocialID, p.ConfigFileName(&user, srv))),
+			Label:     configLabel(srv, p.Interface),
+			Config:    conf,
+		})
+	}
+	return out, errors.Join(errs...)
+}
+
+func configLabel(srv *Server, ifaceName string) string {
+	server, iface := srv.Name, ifaceName
+	if srv.DisplayName != "" {
+		server = srv.DisplayName
+	}
+	for _, i := range srv.Interfaces {
+		if i.Name == ifaceName && i.DisplayName != "" {
+			iface = i.DisplayName
+			break
+		}
+	}
+	return server + " / " + iface
+}
@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGenerateConfigAllowedIPs(t *testing.T) {
+	iface := WireGuardInterface{
+		Name:      "wg0",
+		Endpoint:  "vpn.example.com",
+		Port:      51820,
+		PublicKey: "c2VydmVyLXB1YmxpYy1rZXktYmFzZTY0LWVuY29kZWQ=",
+	}
+	p := PeerState{ServerID: "srv", Interface: "wg0", AllowedAddress: "10.0.0.2/32", PrivateKey: "cGVlci1wcml2YXRlLWtleS1iYXNlNjQtZW5jb2RlZD0="}
+	tests := []struct {
+		name    string
+		iface   []string
+		opts    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "interface routes trimmed", iface: []string{" 10.8.0.0/24 "}, want: "AllowedIPs = 10.8.0.0/24\n"},
+		{name: "option routes trimmed", opts: []string{"10.9.0.0/16 ", " FD00::/8"}, want: "AllowedIPs = 10.9.0.0/16, fd00::/8\n"},
+		{name: "bad interface route", iface: []string{"10.8.0.0/24\nPostUp = rm -rf /"}, wantErr: true},
+		{name: "bad option route", opts: []string{"10.8.0.1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := iface
+			i.AllowedIPs = tt.iface
+			conf, err := p.GenerateConfigWithOptions(&Server{Name: "srv", Interfaces: []WireGuardInterface{i}}, ConfigOptions{AllowedIPs: tt.opts})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("no error; config:\n%s", conf)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(conf, tt.want) {
+				t.Errorf("config lacks %q:\n%s", tt.want, conf)
+			}
+		})
+	}
+}
+
+func TestGenerateConfigNoInterfaces(t *testing.T) {
+	p := PeerState{ServerID: "srv", Interface: "wg0", AllowedAddress: "10.0.0.2/32", PrivateKey: "key"}
+	if _, err := p.GenerateConfig(&Server{Name: "srv"}); !errors.Is(err, ErrServerNoInterfaces) {
+		t.Fatalf("err = %v, want ErrServerNoInterfaces", err)
+	}
+}
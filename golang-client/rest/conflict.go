@@ -0,0 +1,33 @@
+package rest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Version fingerprints the editable fields of the user (traffic limit,
+// duration, social ID and active flag) for EditUserIfUnchanged. The API has
+// no revision counter, and usage is left out as it moves on its own.
+func (u User) Version() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%d|%d|%t", u.TrafficLimit, u.DurationSeconds, u.SocialID, u.Active)
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// EditUserIfUnchanged applies params only if the user's Version still
+// equals expectedVersion, and fails with ErrConflict otherwise. The server
+// has no precondition support, so this is a re-read right before the edit:
+// it catches edits made since the caller's read, but not one racing the
+// final few milliseconds.
+func (c *Client) EditUserIfUnchanged(ctx context.Context, userKey string, params EditUserParams, expectedVersion string) (User, error) {
+	cur, err := c.GetUser(ctx, userKey)
+	if err != nil {
+		return User{}, err
+	}
+	if v := cur.Version(); v != expectedVersion {
+		return User{}, fmt.Errorf("%w: user %s is at version %s, expected %s", ErrConflict, userKey, v, expectedVersion)
+	}
+	return c.EditUserWithParams(ctx, userKey, params)
+}
@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestEditUserIfUnchanged(t *testing.T) {
+	stored := User{UserKey: "k", SocialID: 7, TrafficLimit: 10 << 30}
+	limit := int64(20 << 30)
+	params := EditUserParams{TrafficLimit: &limit}
+
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  error
+	}{
+		{"unchanged", stored.Version(), nil},
+		{"changed since read", (User{UserKey: "k", SocialID: 7, TrafficLimit: 5 << 30}).Version(), ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newTestClient(t)
+			srv.Handle(http.MethodGet, "/api/users/k", resttest.JSON(http.StatusOK, map[string]any{"result": stored}))
+			edited := stored
+			edited.TrafficLimit = limit
+			srv.Handle(http.MethodPatch, "/api/users/k", resttest.JSON(http.StatusOK, map[string]any{"result": edited}))
+
+			u, err := c.EditUserIfUnchanged(context.Background(), "k", params, tt.expected)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			var patches int
+			for _, r := range srv.Requests() {
+				if r.Method == http.MethodPatch {
+					patches++
+				}
+			}
+			if tt.wantErr != nil {
+				if patches != 0 {
+					t.Errorf("sent %d edits on a conflict", patches)
+				}
+				return
+			}
+			if patches != 1 || u.TrafficLimit != limit {
+				t.Errorf("sent %d edits, user = %+v; want one edit applied", patches, u)
+			}
+		})
+	}
+}
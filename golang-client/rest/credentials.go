@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"context"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/creds"
+)
+
+// WithCredentials makes requests and logins run with ctx act as another
+// account than the configured one, so one client can serve many tenants.
+// Each credential set gets its own token, logged in on first use and kept
+// for the life of the client; tokens are never shared between sets. Cached
+// server reads are kept per set as well.
+func WithCredentials(ctx context.Context, username, password string) context.Context {
+	return creds.With(ctx, creds.Set{Username: username, Password: password})
+}
+
+// credentials returns the username and password to log in with for ctx.
+func (c *Client) credentials(ctx context.Context) (string, string) {
+	if s, ok := creds.From(ctx); ok {
+		return s.Username, s.Password
+	}
+	return c.username, c.password
+}
+
+// session returns the token and its expiry used for requests with ctx.
+func (c *Client) session(ctx context.Context) (string, time.Time) {
+	if s, ok := creds.From(ctx); ok {
+		t := c.tenants.Get(s)
+		return t.Value, t.Exp
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token, c.tokenExp
+}
+
+func (c *Client) setSession(ctx context.Context, tok string, exp time.Time) {
+	if s, ok := creds.From(ctx); ok {
+		c.tenants.Put(s, creds.Token{Value: tok, Exp: exp})
+		return
+	}
+	c.setToken(tok, exp)
+}
@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is a request the server answered with a non-2xx status. Message
+// is the "error" field of a JSON body, when there is one.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string
+	Body       []byte
+	Message    string
+}
+
+func newAPIError(method, path string, status int, body []byte) *APIError {
+	e := &APIError{StatusCode: status, Method: method, Path: path, Body: body}
+	var msg struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &msg) == nil {
+		e.Message = msg.Error
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error: %s %s status=%d body=%s", e.Method, e.Path, e.StatusCode, string(e.Body))
+}
+
+// StatusCode returns the HTTP status of an *APIError in err's chain, or 0.
+func StatusCode(err error) int {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
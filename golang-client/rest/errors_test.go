@@ -0,0 +1,47 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestAPIError(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodGet, "/api/users/taken", resttest.JSON(http.StatusConflict, map[string]any{"ok": false, "error": "user exists"}))
+
+	_, err := c.GetUser(context.Background(), "taken")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusConflict || apiErr.Method != http.MethodGet ||
+		apiErr.Path != "/api/users/taken" || apiErr.Message != "user exists" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if !IsConflict(err) || IsNotFound(err) {
+		t.Errorf("IsConflict = %v, IsNotFound = %v; want true, false", IsConflict(err), IsNotFound(err))
+	}
+
+	_, err = c.GetUser(context.Background(), "missing")
+	if !IsNotFound(err) {
+		t.Errorf("unrouted path: err = %v, want a 404", err)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	wrapped := fmt.Errorf("outer: %w", &APIError{StatusCode: http.StatusTeapot})
+	if got := StatusCode(wrapped); got != http.StatusTeapot {
+		t.Errorf("StatusCode(wrapped) = %d, want %d", got, http.StatusTeapot)
+	}
+	if got := StatusCode(errors.New("plain")); got != 0 {
+		t.Errorf("StatusCode(plain) = %d, want 0", got)
+	}
+	if got := StatusCode(nil); got != 0 {
+		t.Errorf("StatusCode(nil) = %d, want 0", got)
+	}
+}
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// exportFlushRows is how many rows ExportUsersCSV buffers between flushes.
+const exportFlushRows = 100
+
+var exportHeader = []string{
+	"user_key", "social_id", "active", "traffic_limit", "usage", "duration_seconds",
+	"created_at", "first_connected_at", "last_connected_at", "created_by",
+}
+
+// ExportUsersCSV writes the users matching opt to w as CSV, one row per user
+// and without peers or sub tokens. It starts cursor users into the listing,
+// writing the header row only when cursor is 0, and returns the cursor to
+// resume from. Rows are flushed to w every exportFlushRows rows and once more
+// on the way out, and the cursor counts the rows flushed, on success, error or
+// ctx ending alike; after a write error w may hold part of the rows past it.
+// Nothing is persisted; a job checkpoints the returned cursor and passes it
+// back with the same opt. Sort by SortByCreatedAt with OrderAsc so users
+// created meanwhile don't shift the positions.
+func (c *Client) ExportUsersCSV(ctx context.Context, w io.Writer, opt ListUsersOptions, cursor int) (int, error) {
+	cw := csv.NewWriter(w)
+	if cursor == 0 {
+		cw.Write(exportHeader)
+	}
+	// rows written since the last flush, counted into cursor once flushed
+	pending := 0
+	flush := func() error {
+		if cw.Flush(); cw.Error() != nil {
+			return cw.Error()
+		}
+		cursor += pending
+		pending = 0
+		return nil
+	}
+
+	opt.Skip = cursor
+	it := c.UsersIterator(opt)
+	var err error
+	for {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		u, ok, nextErr := it.Next(ctx)
+		if err = nextErr; err != nil || !ok {
+			break
+		}
+
+		cw.Write([]string{
+			u.UserKey,
+			strconv.FormatInt(u.SocialID, 10),
+			strconv.FormatBool(u.Active),
+			strconv.FormatInt(u.TrafficLimit, 10),
+			strconv.FormatInt(u.Usage, 10),
+			strconv.FormatInt(u.DurationSeconds, 10),
+			strconv.FormatInt(u.CreatedAt, 10),
+			strconv.FormatInt(u.FirstConnectedAt, 10),
+			strconv.FormatInt(u.LastConnectedAt, 10),
+			u.CreatedBy,
+		})
+		if pending++; pending < exportFlushRows {
+			continue
+		}
+		if err = flush(); err != nil {
+			break
+		}
+	}
+	// hand over the rows buffered when the loop ended, whatever ended it
+	if flushErr := flush(); err == nil {
+		err = flushErr
+	}
+	return cursor, err
+}
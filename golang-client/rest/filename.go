@@ -0,0 +1,14 @@
+package rest
+
+import "github.com/wyronapp/wyron-public/golang-client/internal/wgconfig"
+
+// ConfigFileName returns a wg-quick compatible interface name for the peer,
+// usable both as the kernel interface name and as "<name>.conf". It is built
+// from the server ID and interface name; server may be nil.
+func (p PeerState) ConfigFileName(_ *User, server *Server) string {
+	id := p.ServerID
+	if server != nil && server.Name != "" {
+		id = server.Name
+	}
+	return wgconfig.InterfaceName(id, p.Interface)
+}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	var mu sync.Mutex
+	var got []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = append(got, r.Header.Clone())
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": "tok"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer srv.Close()
+
+	c, err := NewClientFromConfig(Config{
+		BaseURL:  srv.URL,
+		Username: "u",
+		Password: "p",
+		Headers:  map[string]string{"X-Api-Key": "secret", "Content-Type": "text/plain"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("got %d requests, want the login and the call", len(got))
+	}
+	for i, h := range got {
+		if v := h.Get("X-Api-Key"); v != "secret" {
+			t.Errorf("request %d: X-Api-Key = %q, want secret", i, v)
+		}
+	}
+	// the login sets its own Content-Type, which the static one doesn't replace
+	if v := got[0].Get("Content-Type"); v != "application/json" {
+		t.Errorf("login Content-Type = %q, want application/json", v)
+	}
+}
+
+func TestHeadersRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		headers map[string]string
+	}{
+		{name: "auth header", headers: map[string]string{"authorization": "Bearer x"}},
+		{name: "custom auth header", cfg: Config{AuthHeaderName: "X-Token"}, headers: map[string]string{"x-token": "x"}},
+		{name: "user agent", headers: map[string]string{"User-Agent": "x"}},
+		{name: "bad name", headers: map[string]string{"X Key": "x"}},
+		{name: "bad value", headers: map[string]string{"X-Key": "a\r\nX-Other: b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.BaseURL, cfg.Username, cfg.Password = "http://wyron.invalid", "u", "p"
+			cfg.Headers = tt.headers
+			if _, err := NewClientFromConfig(cfg); err == nil || !strings.Contains(err.Error(), "header") {
+				t.Fatalf("err = %v, want a header error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// UserIterator pages through ListUsers results. It follows rel="next" Link
+// headers when the server sends them and otherwise advances skip by the page
+// size, stopping on an empty page or once the total count is reached. A
+// short page doesn't end it, as the server may cap the page size below
+// Limit.
+type UserIterator struct {
+	c    *Client
+	opt  ListUsersOptions
+	next string
+
+	page  []User
+	pos   int
+	total int
+	seen  int
+	done  bool
+}
+
+func (c *Client) UsersIterator(opt ListUsersOptions) *UserIterator {
+	if opt.Limit == 0 {
+		opt.Limit = 50
+	}
+	// the total counts the skipped users too
+	return &UserIterator{c: c, opt: opt, total: -1, seen: opt.Skip}
+}
+
+// Next returns the next user, fetching a page when the current one is used
+// up. It reports false once all users have been returned.
+func (it *UserIterator) Next(ctx context.Context) (*User, bool, error) {
+	for it.pos >= len(it.page) {
+		if it.done {
+			return nil, false, nil
+		}
+		if err := it.fetch(ctx); err != nil {
+			return nil, false, err
+		}
+	}
+	u := &it.page[it.pos]
+	it.pos++
+	return u, true, nil
+}
+
+// Total returns the number of matching users, as ListUsersWithCount finds
+// it, once the first page is fetched.
+func (it *UserIterator) Total() (int, bool) {
+	return it.total, it.total >= 0
+}
+
+func (it *UserIterator) fetch(ctx context.Context) error {
+	full := it.next
+	if full == "" {
+		if err := it.opt.validate(); err != nil {
+			return err
+		}
+		full = it.c.baseURL + it.c.usersPath("/users") + "?" + it.opt.query().Encode()
+	}
+
+	var out usersPage
+	resp, err := it.c.doURL(ctx, http.MethodGet, full, nil, &out)
+	if err != nil {
+		return err
+	}
+	if n := out.total(resp.Header); n >= 0 {
+		it.total = n
+	}
+
+	it.page, it.pos = out.Result, 0
+	it.seen += len(out.Result)
+
+	if links := resp.Header.Values("Link"); len(links) > 0 {
+		next, err := it.resolve(nextLink(links))
+		if err != nil {
+			return err
+		}
+		it.next = next
+		it.done = next == "" || len(out.Result) == 0
+		return nil
+	}
+
+	it.opt.Skip += len(out.Result)
+	it.done = len(out.Result) == 0 || it.total >= 0 && it.seen >= it.total
+	return nil
+}
+
+// resolve makes a next link absolute and refuses links off the API host, so
+// the auth header is never sent elsewhere.
+func (it *UserIterator) resolve(ref string) (string, error) {
+	if ref == "" {
+		return "", nil
+	}
+	base, err := url.Parse(it.c.baseURL + "/")
+	if err != nil {
+		return "", err
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != base.Scheme || u.Host != base.Host {
+		return "", fmt.Errorf("next link points to another host: %s", u.Redacted())
+	}
+	return u.String(), nil
+}
+
+// nextLink returns the rel="next" target of RFC 8288 Link header values.
+func nextLink(values []string) string {
+	for _, v := range values {
+		for _, link := range strings.Split(v, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			target = strings.TrimSpace(target)
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, p := range strings.Split(params, ";") {
+				k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+				if !strings.EqualFold(k, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(v, `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// StreamUsers pages through the users matching opt in the background and
+// sends them one by one. Both channels are closed when paging ends; a
+// failure, including ctx ending, is sent on the error channel first.
+func (c *Client) StreamUsers(ctx context.Context, opt ListUsersOptions) (<-chan User, <-chan error) {
+	users := make(chan User)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(users)
+
+		it := c.UsersIterator(opt)
+		for {
+			u, ok, err := it.Next(ctx)
+			if err != nil {
+				errc <- err
+				return
+			}
+			if !ok {
+				return
+			}
+			select {
+			case users <- *u:
+			case <-ctx.Done():
+				errc <- ctx.Err()
+				return
+			}
+		}
+	}()
+	return users, errc
+}
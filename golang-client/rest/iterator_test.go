@@ -0,0 +1,196 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestListUsersWithCount(t *testing.T) {
+	users := []User{{UserKey: "a"}, {UserKey: "b"}}
+	withHeader := resttest.JSON(http.StatusOK, map[string]any{"result": users, "count": 7})
+	withHeader.Header.Set("X-Total-Count", "42")
+	tests := []struct {
+		name string
+		resp resttest.Response
+		want int
+	}{
+		{"header wins", withHeader, 42},
+		{"count field", resttest.JSON(http.StatusOK, map[string]any{"result": users, "count": 7}), 7},
+		{"total field", resttest.JSON(http.StatusOK, map[string]any{"result": users, "total": 9}), 9},
+		{"none", resttest.JSON(http.StatusOK, map[string]any{"result": users}), -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newTestClient(t)
+			srv.Handle(http.MethodGet, "/api/users", tt.resp)
+
+			got, total, err := c.ListUsersWithCount(context.Background(), ListUsersOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(users) || total != tt.want {
+				t.Errorf("got %d users, total %d; want %d, %d", len(got), total, len(users), tt.want)
+			}
+		})
+	}
+}
+
+func TestUsersIteratorTotal(t *testing.T) {
+	srv, c := newTestClient(t)
+	page := func(keys ...string) resttest.Response {
+		users := make([]User, len(keys))
+		for i, k := range keys {
+			users[i] = User{UserKey: k}
+		}
+		return resttest.JSON(http.StatusOK, map[string]any{"result": users, "total": 5})
+	}
+	srv.Script(http.MethodGet, "/api/users", page("a", "b"), page("c", "d"), page("e"))
+
+	socialID := int64(7)
+	it := c.UsersIterator(ListUsersOptions{
+		SocialID: &socialID,
+		Status:   "active",
+		Search:   "x",
+		Limit:    2,
+		SortBy:   SortByUsage,
+		OrderBy:  OrderAsc,
+	})
+	var keys []string
+	for {
+		u, ok, err := it.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		keys = append(keys, u.UserKey)
+	}
+	if !slices.Equal(keys, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("users = %v, want a to e", keys)
+	}
+	if total, ok := it.Total(); !ok || total != 5 {
+		t.Errorf("Total() = %d, %v; want 5", total, ok)
+	}
+
+	// the reported total ends the iteration without asking for an empty page
+	reqs := srv.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("%d requests, want 3", len(reqs))
+	}
+	for n, r := range reqs {
+		q, err := url.ParseQuery(r.Query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := url.Values{
+			"social_id": {"7"}, "status": {"active"}, "search": {"x"},
+			"limit": {"2"}, "skip": {strconv.Itoa(2 * n)}, "sort": {"usage"}, "order": {"asc"},
+		}
+		for k := range want {
+			if q.Get(k) != want.Get(k) {
+				t.Errorf("request %d: %s = %q, want %q", n, k, q.Get(k), want.Get(k))
+			}
+		}
+	}
+}
+
+func TestUsersIteratorFollowsLinks(t *testing.T) {
+	srv, c := newTestClient(t)
+	page := func(key, link string) resttest.Response {
+		r := resttest.JSON(http.StatusOK, map[string]any{"result": []User{{UserKey: key}}})
+		if link != "" {
+			r.Header.Set("Link", link)
+		}
+		return r
+	}
+	srv.Script(http.MethodGet, "/api/users",
+		// absolute, after a link with another rel
+		page("a", `</api/users?cursor=z>; rel="last", <`+srv.URL+`/api/users?cursor=b>; rel="next"`),
+		// relative, with several rels on one link
+		page("b", `</api/users?cursor=c>; rel="prev next"`),
+		// links, but none to a next page
+		page("c", `</api/users?cursor=b>; rel="prev"`),
+	)
+
+	it := c.UsersIterator(ListUsersOptions{Limit: 1})
+	var keys []string
+	for {
+		u, ok, err := it.Next(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			break
+		}
+		keys = append(keys, u.UserKey)
+	}
+	if !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Errorf("users = %v, want a, b and c", keys)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 3 {
+		t.Fatalf("%d requests, want 3", len(reqs))
+	}
+	for i, want := range []string{"cursor=b", "cursor=c"} {
+		if got := reqs[i+1].Query; got != want {
+			t.Errorf("request %d query = %q, want %q", i+2, got, want)
+		}
+	}
+}
+
+func TestUsersIteratorRefusesForeignLink(t *testing.T) {
+	srv, c := newTestClient(t)
+	r := resttest.JSON(http.StatusOK, map[string]any{"result": []User{{UserKey: "a"}}})
+	r.Header.Set("Link", `<https://elsewhere.example.com/api/users?cursor=b>; rel="next"`)
+	srv.Handle(http.MethodGet, "/api/users", r)
+
+	_, _, err := c.UsersIterator(ListUsersOptions{}).Next(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "another host") {
+		t.Fatalf("err = %v, want a refused next link", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("%d requests, want 1", n)
+	}
+}
+
+func TestStreamUsersStopsOnCancel(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodGet, "/api/users", resttest.JSON(http.StatusOK, map[string]any{
+		"result": []User{{UserKey: "a"}, {UserKey: "b"}, {UserKey: "c"}}, "total": 3,
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	users, errc := c.StreamUsers(ctx, ListUsersOptions{Limit: 3})
+	if u, ok := <-users; !ok || u.UserKey != "a" {
+		t.Fatalf("first user = %v, %v; want a", u, ok)
+	}
+
+	// the consumer stops reading while the next user is pending
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("paging goroutine still running after cancel")
+	}
+	if _, ok := <-users; ok {
+		t.Error("users channel still open")
+	}
+	if _, ok := <-errc; ok {
+		t.Error("error channel still open")
+	}
+}
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/wgconfig"
+)
+
+// PublicKey derives the peer's public key from its PrivateKey.
+func (p PeerState) PublicKey() (string, error) {
+	if p.PrivateKey == "" {
+		return "", ErrInterfaceMissingKey
+	}
+	pub, err := wgconfig.PublicKey(p.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return pub, nil
+}
+
+// PublicKeyFingerprint returns a short fingerprint of the interface's public
+// key for users to cross-check, or "" when the key is missing or invalid;
+// PublicKeyFingerprintErr says why.
+func (i WireGuardInterface) PublicKeyFingerprint() string {
+	fp, _ := i.PublicKeyFingerprintErr()
+	return fp
+}
+
+func (i WireGuardInterface) PublicKeyFingerprintErr() (string, error) {
+	if i.PublicKey == "" {
+		return "", fmt.Errorf("%w: public_key missing", ErrInterfaceMissingKey)
+	}
+	fp, err := wgconfig.Fingerprint(i.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return fp, nil
+}
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestRequestLogger(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	srv.Handle(http.MethodGet, "/api/servers", resttest.JSON(http.StatusOK, map[string]any{"data": []any{}}))
+
+	var mu sync.Mutex
+	var lines []string
+	c, err := NewClientFromConfig(Config{
+		BaseURL:  srv.URL,
+		Username: "u",
+		Password: "p",
+		RequestLogger: func(method, path string, status int, took time.Duration) {
+			if took < 0 {
+				t.Errorf("%s %s: negative duration", method, path)
+			}
+			mu.Lock()
+			lines = append(lines, fmt.Sprintf("%s %s %d", method, path, status))
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := c.ListServers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// the re-login happens within the one logged request
+	srv.ExpireToken()
+	if _, err := c.GetServer(ctx, "missing"); !IsNotFound(err) {
+		t.Fatalf("err = %v, want a 404", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"GET /api/servers 200", "GET /api/servers/missing 404"}
+	if !slices.Equal(lines, want) {
+		t.Errorf("logged %q, want %q", lines, want)
+	}
+}
+
+func TestRequestLoggerNoResponse(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	status := -1
+	c, err := NewClientFromConfig(Config{
+		BaseURL:  srv.URL,
+		Username: "u",
+		Password: "p",
+		RequestLogger: func(_, _ string, s int, _ time.Duration) {
+			status = s
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	if _, err := c.ListServers(context.Background()); err == nil {
+		t.Fatal("no error from a closed server")
+	}
+	if status != 0 {
+		t.Errorf("status = %d, want 0 when no response arrived", status)
+	}
+}
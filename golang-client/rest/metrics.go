@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/op"
+)
+
+// Metrics are the user statistics of /users/metrics. Counts and byte totals
+// decode straight into int64, so large usage values stay exact.
+type Metrics struct {
+	TotalUsers        int64 `json:"total_users"`
+	ActiveUsers       int64 `json:"active_users"`
+	DisabledUsers     int64 `json:"disabled_users"`
+	ExpiredUsers      int64 `json:"expired_users"`
+	LimitedUsers      int64 `json:"limited_users"`
+	TotalUsage        int64 `json:"total_usage"`
+	TotalTrafficLimit int64 `json:"total_traffic_limit"`
+}
+
+// Value returns the metric with the given wire name, e.g. "active_users".
+func (m Metrics) Value(name string) (float64, bool) {
+	switch name {
+	case "total_users":
+		return float64(m.TotalUsers), true
+	case "active_users":
+		return float64(m.ActiveUsers), true
+	case "disabled_users":
+		return float64(m.DisabledUsers), true
+	case "expired_users":
+		return float64(m.ExpiredUsers), true
+	case "limited_users":
+		return float64(m.LimitedUsers), true
+	case "total_usage":
+		return float64(m.TotalUsage), true
+	case "total_traffic_limit":
+		return float64(m.TotalTrafficLimit), true
+	}
+	return 0, false
+}
+
+func (c *Client) Metrics(ctx context.Context) (Metrics, error) {
+	var out Metrics
+	err := c.requestJSON(ctx, "GET", c.usersPath("/users/metrics"), nil, nil, &out)
+	return out, err
+}
+
+// MetricsRaw returns the metrics response undecoded, numbers as float64.
+func (c *Client) MetricsRaw(ctx context.Context) (map[string]any, error) {
+	var out map[string]any
+	err := c.requestJSON(ctx, "GET", c.usersPath("/users/metrics"), nil, nil, &out)
+	return out, err
+}
+
+// MetricValue fetches the metrics and returns the one named, by its wire
+// name such as "active_users".
+func (c *Client) MetricValue(ctx context.Context, name string) (float64, error) {
+	m, err := c.Metrics(ctx)
+	if err != nil {
+		return 0, err
+	}
+	v, ok := m.Value(name)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownMetric, name)
+	}
+	return v, nil
+}
+
+// OperationName names a request by its method and URL path the way
+// MetricsObserver does, e.g. "users.List" for GET /api/users.
+func OperationName(method, path string) string {
+	return op.FromHTTP(method, path)
+}
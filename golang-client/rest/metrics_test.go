@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+// bigUsage does not fit a float64 exactly.
+const bigUsage = 1<<53 + 1
+
+func newMetricsServer(t *testing.T) (*resttest.Server, *Client) {
+	t.Helper()
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodGet, "/api/users/metrics", resttest.Response{
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   `{"total_users": 3, "active_users": 2, "total_usage": 9007199254740993}`,
+	})
+	return srv, c
+}
+
+func TestMetrics(t *testing.T) {
+	_, c := newMetricsServer(t)
+	ctx := context.Background()
+
+	m, err := c.Metrics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.TotalUsers != 3 || m.ActiveUsers != 2 || m.TotalUsage != bigUsage {
+		t.Errorf("Metrics = %+v", m)
+	}
+
+	if v, err := c.MetricValue(ctx, "active_users"); err != nil || v != 2 {
+		t.Errorf("MetricValue(active_users) = %v, %v", v, err)
+	}
+	if _, err := c.MetricValue(ctx, "nope"); !errors.Is(err, ErrUnknownMetric) {
+		t.Errorf("MetricValue(nope) err = %v, want ErrUnknownMetric", err)
+	}
+}
+
+func TestMetricsRecorderKeepsTypedSamples(t *testing.T) {
+	_, c := newMetricsServer(t)
+	r := NewMetricsRecorder(c, time.Hour, 2)
+	for range 3 {
+		r.sample(context.Background())
+	}
+	if err := r.LastError(); err != nil {
+		t.Fatal(err)
+	}
+	series := r.Series()
+	if len(series) != 2 {
+		t.Fatalf("got %d points, want the ring size 2", len(series))
+	}
+	if got := series[1].Metrics.TotalUsage; got != bigUsage {
+		t.Errorf("TotalUsage = %d, want %d", got, int64(bigUsage))
+	}
+}
@@ -0,0 +1,187 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/retry"
+)
+
+// Middleware wraps the next round tripper of the request chain. Configured
+// middlewares run outermost first; the built-in re-login, auth and retry
+// layers sit innermost, right above the HTTP transport. Login requests only pass through
+// the configured middlewares.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func chain(base http.RoundTripper, mws ...Middleware) http.RoundTripper {
+	for i := len(mws) - 1; i >= 0; i-- {
+		base = mws[i](base)
+	}
+	return base
+}
+
+func (c *Client) limitMiddleware(next http.RoundTripper) http.RoundTripper {
+	if c.limiter == nil {
+		return next
+	}
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := c.limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+// headerMiddleware sets the client-wide headers on every request, logins
+// included.
+func (c *Client) headerMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+		for k, v := range c.cfg.Headers {
+			if req.Header.Get(k) == "" {
+				req.Header.Set(k, v)
+			}
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+func (c *Client) authMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		c.authHeader(req)
+		return next.RoundTrip(req)
+	})
+}
+
+func (c *Client) reloginMiddleware(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+
+		if err := c.refreshIfExpiring(ctx); err != nil {
+			return nil, err
+		}
+
+		used, _ := c.session(ctx)
+		resp, err := next.RoundTrip(req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+
+		// auto re-login on 401, unless the server says it won't help
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+		if code, fatal := c.fatalAuthCode(resp.Header, raw); fatal {
+			return nil, fmt.Errorf("%w: %s %s: %s", ErrAccountDisabled, req.Method, req.URL.Path, code)
+		}
+		if err := c.reloginAfter(ctx, used); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", req.Method, req.URL.Path, err)
+		}
+
+		retry := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			retry.Body = body
+		}
+		return next.RoundTrip(retry)
+	})
+}
+
+// fatalAuthCode returns the reason of a 401 and whether it is one of
+// FatalAuthCodes.
+func (c *Client) fatalAuthCode(h http.Header, body []byte) (string, bool) {
+	if len(c.cfg.FatalAuthCodes) == 0 {
+		return "", false
+	}
+	code := ""
+	if c.cfg.AuthErrorHeader != "" {
+		code = h.Get(c.cfg.AuthErrorHeader)
+	}
+	if code == "" {
+		var fields map[string]any
+		if json.Unmarshal(body, &fields) == nil {
+			code, _ = fields[c.cfg.AuthErrorField].(string)
+		}
+	}
+	if code == "" {
+		return "", false
+	}
+	for _, fatal := range c.cfg.FatalAuthCodes {
+		if strings.EqualFold(code, fatal) {
+			return code, true
+		}
+	}
+	return code, false
+}
+
+// retryMiddleware retries transient failures as configured by Config.Retry.
+func (c *Client) retryMiddleware(next http.RoundTripper) http.RoundTripper {
+	p := c.cfg.Retry
+	if !p.Enabled() {
+		return next
+	}
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		for n := 1; ; n++ {
+			resp, err := next.RoundTrip(req)
+			if n >= p.MaxAttempts || !transient(ctx, resp, err) || !p.NonIdempotent && !idempotent(req.Method) {
+				return resp, err
+			}
+			d := p.Delay(n)
+			// a body that can't be replayed can't be sent again
+			if !retry.Fits(ctx, d) || req.Body != nil && req.GetBody == nil {
+				return resp, err
+			}
+			if resp != nil {
+				_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+				resp.Body.Close()
+			}
+			if err := retry.Wait(ctx, d); err != nil {
+				return nil, err
+			}
+			if req.Body != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req = req.Clone(ctx)
+				req.Body = body
+			}
+		}
+	})
+}
+
+// transient reports a connection failure, other than ctx ending, or a
+// gateway status the next attempt may not see.
+func transient(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
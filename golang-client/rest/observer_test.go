@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+type observation struct {
+	op     string
+	status int
+}
+
+func TestMetricsObserver(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	srv.Handle(http.MethodGet, "/api/servers", resttest.JSON(http.StatusOK, map[string]any{"data": []any{}}))
+
+	var got []observation
+	c, err := NewClientFromConfig(Config{
+		BaseURL:  srv.URL,
+		Username: "u",
+		Password: "p",
+		MetricsObserver: func(op string, took time.Duration, err error) {
+			got = append(got, observation{op, StatusCode(err)})
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// one observation per call, after the re-login
+	srv.ExpireToken()
+	if _, err := c.ListServers(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetServer(ctx, "missing"); !IsNotFound(err) {
+		t.Fatalf("err = %v, want a 404", err)
+	}
+
+	want := []observation{{"servers.List", 0}, {"servers.Get", http.StatusNotFound}}
+	if len(got) != len(want) {
+		t.Fatalf("observed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("observation %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
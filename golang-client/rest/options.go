@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"maps"
+	"time"
+)
+
+// Option sets Config fields for New. Options apply in order, so a later one
+// wins; fields without a dedicated option can be set by any func(*Config)
+// converted to an Option.
+type Option func(*Config)
+
+// New builds a client for baseURL from options. WithLogin is required and
+// New fails with ErrMissingCredentials without it; every other setting
+// keeps its Config default.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	cfg := Config{BaseURL: baseURL}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	if cfg.Username == "" || cfg.Password == "" {
+		return nil, ErrMissingCredentials
+	}
+	return NewClientFromConfig(cfg)
+}
+
+// WithLogin sets the credentials the client logs in with. It is unrelated
+// to WithCredentials, which swaps them for a single context.
+func WithLogin(username, password string) Option {
+	return func(c *Config) {
+		c.Username, c.Password = username, password
+	}
+}
+
+// WithProxy sends requests through an HTTP or SOCKS5 proxy URL.
+func WithProxy(proxyURL string) Option {
+	return func(c *Config) { c.ProxyURL = proxyURL }
+}
+
+// WithTimeout bounds requests whose context has no deadline.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Config) { c.Timeout = d }
+}
+
+// WithMiddleware appends to the request middleware chain.
+func WithMiddleware(mws ...Middleware) Option {
+	return func(c *Config) { c.Middleware = append(c.Middleware, mws...) }
+}
+
+// WithTLSConfig sets the base TLS config for the backend connection.
+func WithTLSConfig(tc *tls.Config) Option {
+	return func(c *Config) { c.TLSConfig = tc }
+}
+
+// WithRootCAs trusts the CAs in pool instead of the system roots.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(c *Config) { c.RootCAs = pool }
+}
+
+// WithInsecureSkipVerify turns off certificate verification. Use it only
+// against development backends.
+func WithInsecureSkipVerify(skip bool) Option {
+	return func(c *Config) { c.InsecureSkipVerify = skip }
+}
+
+// WithRateLimit caps outgoing requests to perSecond with bursts of up to
+// burst; see Config.RateLimit.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *Config) { c.RateLimit, c.RateBurst = perSecond, burst }
+}
+
+// WithUserAgent sets the User-Agent sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Config) { c.UserAgent = ua }
+}
+
+// WithHeader adds a header sent with every request; see Config.Headers.
+func WithHeader(key, value string) Option {
+	return func(c *Config) {
+		// copied so a map the caller set isn't changed behind their back
+		h := maps.Clone(c.Headers)
+		if h == nil {
+			h = make(map[string]string)
+		}
+		h[key] = value
+		c.Headers = h
+	}
+}
+
+// WithRequestLogger sets Config.RequestLogger.
+func WithRequestLogger(log func(method, path string, status int, took time.Duration)) Option {
+	return func(c *Config) { c.RequestLogger = log }
+}
+
+// WithMetricsObserver sets Config.MetricsObserver.
+func WithMetricsObserver(obs func(op string, took time.Duration, err error)) Option {
+	return func(c *Config) { c.MetricsObserver = obs }
+}
@@ -0,0 +1,119 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Field names used in FieldError, matching the wire names of the request.
+const (
+	FieldUserKey         = "user_key"
+	FieldTrafficLimit    = "traffic_limit"
+	FieldDurationSeconds = "duration_seconds"
+	FieldSocialID        = "social_id"
+	FieldServerAccess    = "server_access"
+)
+
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func validationError(errs []FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: errs}
+}
+
+type AccessRequest struct {
+	ServerID   string   `json:"server_id"`
+	Interfaces []string `json:"interfaces"`
+}
+
+type CreateUserParams struct {
+	UserKey         string          `json:"user_key,omitempty"`
+	TrafficLimit    int64           `json:"traffic_limit"`
+	DurationSeconds int64           `json:"duration_seconds"`
+	SocialID        int64           `json:"social_id"`
+	ServerAccess    []AccessRequest `json:"server_access,omitempty"`
+}
+
+type EditUserParams struct {
+	TrafficLimit    *int64 `json:"traffic_limit,omitempty"`
+	DurationSeconds *int64 `json:"duration_seconds,omitempty"`
+	SocialID        *int64 `json:"social_id,omitempty"`
+}
+
+func (p CreateUserParams) Validate() []FieldError {
+	var errs []FieldError
+	if p.TrafficLimit < 0 {
+		errs = append(errs, FieldError{FieldTrafficLimit, "must not be negative"})
+	}
+	if p.DurationSeconds < 0 {
+		errs = append(errs, FieldError{FieldDurationSeconds, "must not be negative"})
+	}
+	if p.SocialID <= 0 {
+		errs = append(errs, FieldError{FieldSocialID, "required"})
+	}
+	for i, a := range p.ServerAccess {
+		if a.ServerID == "" {
+			errs = append(errs, FieldError{FieldServerAccess, fmt.Sprintf("entry %d: server_id required", i)})
+		}
+		for _, name := range a.Interfaces {
+			if name == "" {
+				errs = append(errs, FieldError{FieldServerAccess, fmt.Sprintf("entry %d: empty interface name", i)})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+func (p EditUserParams) Validate() []FieldError {
+	var errs []FieldError
+	if p.TrafficLimit != nil && *p.TrafficLimit < 0 {
+		errs = append(errs, FieldError{FieldTrafficLimit, "must not be negative"})
+	}
+	if p.DurationSeconds != nil && *p.DurationSeconds < 0 {
+		errs = append(errs, FieldError{FieldDurationSeconds, "must not be negative"})
+	}
+	if p.SocialID != nil && *p.SocialID <= 0 {
+		errs = append(errs, FieldError{FieldSocialID, "must be positive"})
+	}
+	return errs
+}
+
+func withAccess(access []AccessRequest, serverID, iface string) []AccessRequest {
+	out := make([]AccessRequest, len(access))
+	copy(out, access)
+	for i, a := range out {
+		if a.ServerID != serverID {
+			continue
+		}
+		for _, name := range a.Interfaces {
+			if name == iface {
+				return out
+			}
+		}
+		out[i].Interfaces = append(append([]string{}, a.Interfaces...), iface)
+		return out
+	}
+	return append(out, AccessRequest{ServerID: serverID, Interfaces: []string{iface}})
+}
@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestProbeServers(t *testing.T) {
+	// a silent listener stands in for WireGuard, which never answers
+	live, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+	livePort := live.LocalAddr().(*net.UDPAddr).Port
+
+	closed, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.LocalAddr().(*net.UDPAddr).Port
+	closed.Close()
+
+	servers := []Server{{
+		Name: "srv",
+		Interfaces: []WireGuardInterface{
+			{Name: "live", Endpoint: "127.0.0.1", Port: livePort},
+			{Name: "closed", Endpoint: "127.0.0.1", Port: closedPort},
+			{Name: "nohost", Endpoint: "wyron-probe-test.invalid", Port: 51820},
+			{Name: "noport", Endpoint: "192.0.2.1"},
+		},
+	}}
+	got := probeServers(context.Background(), servers, 200*time.Millisecond)
+	if len(got) != 4 {
+		t.Fatalf("got %d results, want 4", len(got))
+	}
+
+	if st := got[0]; !st.NotRefused || st.Err != nil || st.Endpoint != "127.0.0.1:"+strconv.Itoa(livePort) {
+		t.Errorf("silent listener: %+v", st)
+	}
+	if st := got[1]; st.NotRefused || st.Err == nil {
+		t.Errorf("closed port: %+v", st)
+	}
+	if st := got[2]; st.NotRefused || st.Err == nil {
+		t.Errorf("unresolvable host: %+v", st)
+	}
+	if st := got[3]; st.NotRefused || !errors.Is(st.Err, ErrInterfaceMissingKey) {
+		t.Errorf("missing port: %+v", st)
+	}
+}
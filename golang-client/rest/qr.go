@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/wgconfig"
+)
+
+// QROptions tunes GenerateConfigQR and GenerateConfigQRString; it is shared
+// with the other transport.
+type QROptions = wgconfig.QROptions
+
+// GenerateConfigQR renders the peer's config as a PNG QR code for the
+// WireGuard mobile apps to scan. It fails like GenerateConfigWithOptions
+// when the peer or its interface is incomplete.
+func (p PeerState) GenerateConfigQR(srv *Server, opts QROptions) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOption, err)
+	}
+	conf, err := p.GenerateConfigWithOptions(srv, opts.Config)
+	if err != nil {
+		return nil, err
+	}
+	return wgconfig.QRPNG(conf, opts.Size)
+}
+
+// GenerateConfigQRString is GenerateConfigQR drawn with block characters
+// for a terminal. opts.Size is ignored, as the terminal font sets the size.
+func (p PeerState) GenerateConfigQRString(srv *Server, opts QROptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidOption, err)
+	}
+	conf, err := p.GenerateConfigWithOptions(srv, opts.Config)
+	if err != nil {
+		return "", err
+	}
+	return wgconfig.QRText(conf)
+}
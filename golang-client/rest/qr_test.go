@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/testdata/qrdecode"
+)
+
+func TestGenerateConfigQR(t *testing.T) {
+	srv := &Server{Name: "srv", Interfaces: []WireGuardInterface{{
+		Name:      "wg0",
+		Endpoint:  "vpn.example.com",
+		Port:      51820,
+		PublicKey: "c2VydmVyLXB1YmxpYy1rZXktYmFzZTY0LWVuY29kZWQ=",
+	}}}
+	p := PeerState{ServerID: "srv", Interface: "wg0", AllowedAddress: "10.0.0.2/32", PrivateKey: "key"}
+	opts := QROptions{Config: ConfigOptions{MTU: 1420}, Size: 300}
+
+	want, err := p.GenerateConfigWithOptions(srv, opts.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.GenerateConfigQR(srv, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeQR(t, b); got != want {
+		t.Errorf("code decodes to %q, want the rendered config %q", got, want)
+	}
+
+	if _, err := p.GenerateConfigQR(srv, QROptions{Size: -1}); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("negative size: err = %v, want ErrInvalidOption", err)
+	}
+	if _, err := (PeerState{}).GenerateConfigQR(srv, QROptions{}); !errors.Is(err, ErrInterfaceMissingKey) {
+		t.Errorf("incomplete peer: err = %v, want ErrInterfaceMissingKey", err)
+	}
+	other := p
+	other.Interface = "wg9"
+	if _, err := other.GenerateConfigQR(srv, QROptions{}); !errors.Is(err, ErrInterfaceNotFound) {
+		t.Errorf("missing interface: err = %v, want ErrInterfaceNotFound", err)
+	}
+}
+
+func TestGenerateConfigQRString(t *testing.T) {
+	srv := &Server{Name: "srv", Interfaces: []WireGuardInterface{{
+		Name:      "wg0",
+		Endpoint:  "vpn.example.com",
+		Port:      51820,
+		PublicKey: "c2VydmVyLXB1YmxpYy1rZXktYmFzZTY0LWVuY29kZWQ=",
+	}}}
+	p := PeerState{ServerID: "srv", Interface: "wg0", AllowedAddress: "10.0.0.2/32", PrivateKey: "key"}
+
+	s, err := p.GenerateConfigQRString(srv, QROptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.ContainsAny(s, "█▀▄") {
+		t.Errorf("no block characters in %q", s)
+	}
+
+	if _, err := p.GenerateConfigQRString(srv, QROptions{Config: ConfigOptions{MTU: 1}}); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("bad MTU: err = %v, want ErrInvalidOption", err)
+	}
+	other := p
+	other.Interface = "wg9"
+	if _, err := other.GenerateConfigQRString(srv, QROptions{}); !errors.Is(err, ErrInterfaceNotFound) {
+		t.Errorf("missing interface: err = %v, want ErrInterfaceNotFound", err)
+	}
+}
+
+// decodeQR reads back the text of a PNG QR code.
+func decodeQR(t *testing.T, b []byte) string {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("not a PNG: %v", err)
+	}
+	text, err := qrdecode.Decode(img)
+	if err != nil {
+		t.Fatalf("decoding QR code: %v", err)
+	}
+	return text
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// NoLimit is returned by RemainingTraffic for users without a traffic limit.
+const NoLimit = math.MaxInt64
+
+// RemainingTraffic returns the bytes left before the traffic limit, zero once
+// it is reached, or NoLimit when the user has none.
+func (u User) RemainingTraffic() int64 {
+	if u.TrafficLimit <= 0 {
+		return NoLimit
+	}
+	if u.Usage >= u.TrafficLimit {
+		return 0
+	}
+	return u.TrafficLimit - u.Usage
+}
+
+// UsagePercent returns usage as a percentage of the traffic limit, which may
+// exceed 100, or 0 for users without a limit.
+func (u User) UsagePercent() float64 {
+	if u.TrafficLimit <= 0 {
+		return 0
+	}
+	return float64(u.Usage) / float64(u.TrafficLimit) * 100
+}
+
+// IsOverQuota reports whether the user has used up a traffic limit.
+func (u User) IsOverQuota() bool {
+	return u.TrafficLimit > 0 && u.Usage >= u.TrafficLimit
+}
+
+// UserSummary is what a status card needs about a user, computed at a single
+// instant.
+type UserSummary struct {
+	Active          bool
+	RemainingBytes  int64
+	UsagePercent    float64
+	IsOverQuota     bool
+	DaysUntilExpiry int
+	IsExpired       bool
+	ExpiresAt       time.Time
+}
+
+func (u User) Summary() UserSummary {
+	t := now()
+	return UserSummary{
+		Active:          u.Active,
+		RemainingBytes:  u.RemainingTraffic(),
+		UsagePercent:    u.UsagePercent(),
+		IsOverQuota:     u.IsOverQuota(),
+		DaysUntilExpiry: u.daysUntilExpiry(t),
+		IsExpired:       u.isExpired(t),
+		ExpiresAt:       u.ExpiresAt(),
+	}
+}
+
+// CrossedThreshold returns the usage percentages among thresholds that curr
+// has reached but prev had not, ascending and without repeats, to drive
+// one-off quota alerts. A nil prev counts as no usage, and users without a
+// traffic limit never cross anything.
+func CrossedThreshold(prev, curr *User, thresholds []float64) []float64 {
+	if curr == nil || curr.TrafficLimit <= 0 {
+		return nil
+	}
+	var before float64
+	if prev != nil {
+		before = prev.UsagePercent()
+	}
+	after := curr.UsagePercent()
+
+	var out []float64
+	for _, t := range thresholds {
+		if before < t && after >= t && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
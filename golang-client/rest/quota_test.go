@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRemainingTraffic(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit, usage int64
+		want         int64
+	}{
+		{"no limit", 0, 5 << 30, NoLimit},
+		{"negative limit", -1, 0, NoLimit},
+		{"unused", 10 << 30, 0, 10 << 30},
+		{"partly used", 10 << 30, 4 << 30, 6 << 30},
+		{"at limit", 10 << 30, 10 << 30, 0},
+		{"over limit", 10 << 30, 12 << 30, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := User{TrafficLimit: tt.limit, Usage: tt.usage}
+			if got := u.RemainingTraffic(); got != tt.want {
+				t.Errorf("RemainingTraffic() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsagePercent(t *testing.T) {
+	tests := []struct {
+		name         string
+		limit, usage int64
+		want         float64
+	}{
+		{"no limit", 0, 100, 0},
+		{"unused", 200, 0, 0},
+		{"half", 200, 100, 50},
+		{"over limit", 200, 300, 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := User{TrafficLimit: tt.limit, Usage: tt.usage}
+			if got := u.UsagePercent(); got != tt.want {
+				t.Errorf("UsagePercent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := int64(24 * time.Hour / time.Second)
+	tests := []struct {
+		name        string
+		duration    int64
+		firstConn   int64
+		now         time.Time
+		wantExpires time.Time
+		wantExpired bool
+	}{
+		{"no duration", 0, first.Unix(), first.AddDate(1, 0, 0), time.Time{}, false},
+		{"never connected", 30 * day, 0, first.AddDate(1, 0, 0), time.Time{}, false},
+		{"running", 30 * day, first.Unix(), first.AddDate(0, 0, 10), first.AddDate(0, 0, 30), false},
+		{"at expiry", 30 * day, first.Unix(), first.AddDate(0, 0, 30), first.AddDate(0, 0, 30), true},
+		{"expired", 30 * day, first.Unix(), first.AddDate(0, 0, 31), first.AddDate(0, 0, 30), true},
+	}
+	defer SetClock(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetClock(func() time.Time { return tt.now })
+			u := User{DurationSeconds: tt.duration, FirstConnectedAt: tt.firstConn}
+			if got := u.ExpiresAt(); !got.Equal(tt.wantExpires) {
+				t.Errorf("ExpiresAt() = %v, want %v", got, tt.wantExpires)
+			}
+			if got := u.IsExpired(); got != tt.wantExpired {
+				t.Errorf("IsExpired() = %v, want %v", got, tt.wantExpired)
+			}
+		})
+	}
+}
+
+func TestCrossedThreshold(t *testing.T) {
+	user := func(limit, usage int64) *User { return &User{TrafficLimit: limit, Usage: usage} }
+	thresholds := []float64{90, 50, 80, 50}
+	tests := []struct {
+		name       string
+		prev, curr *User
+		want       []float64
+	}{
+		{"nil prev", nil, user(100, 60), []float64{50}},
+		{"nil curr", user(100, 10), nil, nil},
+		{"unlimited", user(0, 10), user(0, 1000), nil},
+		{"nothing crossed", user(100, 55), user(100, 70), nil},
+		{"exactly at threshold", user(100, 79), user(100, 80), []float64{80}},
+		{"already at threshold", user(100, 80), user(100, 85), nil},
+		{"several, sorted and deduplicated", user(100, 10), user(100, 95), []float64{50, 80, 90}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CrossedThreshold(tt.prev, tt.curr, thresholds); !slices.Equal(got, tt.want) {
+				t.Errorf("CrossedThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
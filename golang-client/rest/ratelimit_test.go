@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	srv.Handle(http.MethodGet, "/api/servers", resttest.JSON(http.StatusOK, map[string]any{"data": []any{}}))
+
+	// the login takes the one burst token
+	c, err := New(srv.URL, WithLogin("u", "p"), WithRateLimit(20, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for range 3 {
+		if _, err := c.ListServers(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if took := time.Since(start); took < 120*time.Millisecond {
+		t.Errorf("3 requests at 20/s took %v, want at least 150ms", took)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.ListServers(ctx); err == nil {
+		t.Error("request succeeded although its deadline came before the next token")
+	}
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("server got %d requests, want 3", n)
+	}
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type MetricsPoint struct {
+	At      time.Time
+	Metrics Metrics
+}
+
+// MetricsRecorder keeps a ring of Metrics samples for trend charts. The Wyron
+// API only reports point-in-time metrics, so history has to be sampled
+// client-side.
+type MetricsRecorder struct {
+	client   *Client
+	interval time.Duration
+
+	mu      sync.Mutex
+	points  []MetricsPoint
+	next    int
+	full    bool
+	lastErr error
+}
+
+// NewMetricsRecorder samples every interval (default 1m) and keeps the last
+// size points (default 1440).
+func NewMetricsRecorder(c *Client, interval time.Duration, size int) *MetricsRecorder {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if size <= 0 {
+		size = 1440
+	}
+	return &MetricsRecorder{
+		client:   c,
+		interval: interval,
+		points:   make([]MetricsPoint, size),
+	}
+}
+
+// Run samples until ctx is done. Failed samples are skipped and reported by
+// LastError.
+func (r *MetricsRecorder) Run(ctx context.Context) error {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	r.sample(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			r.sample(ctx)
+		}
+	}
+}
+
+func (r *MetricsRecorder) sample(ctx context.Context) {
+	m, err := r.client.Metrics(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastErr = err
+	if err != nil {
+		return
+	}
+	r.points[r.next] = MetricsPoint{At: time.Now(), Metrics: m}
+	r.next = (r.next + 1) % len(r.points)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Series returns the recorded points, oldest first.
+func (r *MetricsRecorder) Series() []MetricsPoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]MetricsPoint(nil), r.points[:r.next]...)
+	}
+	out := make([]MetricsPoint, 0, len(r.points))
+	out = append(out, r.points[r.next:]...)
+	return append(out, r.points[:r.next]...)
+}
+
+func (r *MetricsRecorder) LastError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastErr
+}
@@ -0,0 +1,40 @@
+package rest
+
+import "encoding/json"
+
+// GroupServersByRegion groups servers by Region, keeping their order within
+// each group. Servers without a region are grouped under "".
+func GroupServersByRegion(servers []Server) map[string][]Server {
+	out := make(map[string][]Server)
+	for _, s := range servers {
+		out[s.Region] = append(out[s.Region], s)
+	}
+	return out
+}
+
+var serverFields = []string{
+	"name", "address", "username", "display_name", "created_at", "interfaces",
+	"region", "country", "flag",
+}
+
+func (s *Server) UnmarshalJSON(b []byte) error {
+	type plain Server
+	var p plain
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+
+	var all map[string]any
+	if err := json.Unmarshal(b, &all); err != nil {
+		return err
+	}
+	for _, k := range serverFields {
+		delete(all, k)
+	}
+	if len(all) > 0 {
+		p.Metadata = all
+	}
+
+	*s = Server(p)
+	return nil
+}
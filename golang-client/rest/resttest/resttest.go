@@ -0,0 +1,231 @@
+// Package resttest provides a scriptable fake Wyron REST backend for
+// exercising the rest client's re-login and retry paths without a live server.
+package resttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/token"
+)
+
+type Response struct {
+	Status int
+	Header http.Header
+	Body   string
+}
+
+func JSON(status int, v any) Response {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return Response{
+		Status: status,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   string(b),
+	}
+}
+
+func Unauthorized() Response {
+	return JSON(http.StatusUnauthorized, map[string]any{"ok": false, "error": "unauthorized"})
+}
+
+// TooManyRequests returns a 429 carrying Retry-After. The rest client does
+// not retry it; it surfaces as an *rest.APIError for the caller's own backoff
+// to handle.
+func TooManyRequests(retryAfter time.Duration) Response {
+	r := JSON(http.StatusTooManyRequests, map[string]any{"ok": false, "error": "rate limited"})
+	r.Header.Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second)/time.Second)))
+	return r
+}
+
+// Malformed returns a response whose body is truncated JSON.
+func Malformed(status int) Response {
+	return Response{
+		Status: status,
+		Header: http.Header{"Content-Type": []string{"application/json"}},
+		Body:   `{"ok": true, "result": [`,
+	}
+}
+
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	// Authorization is the value of the auth header, whatever its name.
+	Authorization string
+	Body          []byte
+}
+
+// Server answers login requests on any path ending in "/auth/login" with the
+// configured credentials and rejects other requests that do not carry the
+// current token in the auth header, by default "Authorization: Bearer
+// <token>". Scripted responses are served in order per method and path,
+// then the handler registered with Handle, then 404.
+type Server struct {
+	*httptest.Server
+
+	username string
+	password string
+
+	mu         sync.Mutex
+	authName   string
+	authScheme string
+	token      string
+	seq        int
+	logins     int
+	scripts    map[string][]Response
+	handlers   map[string]Response
+	requests   []Request
+}
+
+func NewServer(username, password string) *Server {
+	s := &Server{
+		username:   username,
+		password:   password,
+		authName:   token.DefaultHeader,
+		authScheme: token.DefaultScheme,
+		scripts:    make(map[string][]Response),
+		handlers:   make(map[string]Response),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SetAuthHeader makes the server expect the token in header name with the
+// given scheme, to match rest.Config AuthHeaderName and AuthScheme, which
+// default the same way. It panics on an invalid name or scheme.
+func (s *Server) SetAuthHeader(name, scheme string) {
+	name, scheme, err := token.Header(name, scheme)
+	if err != nil {
+		panic(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authName, s.authScheme = name, scheme
+}
+
+// Script queues responses for method and path, served once each in order.
+func (s *Server) Script(method, path string, rs ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := routeKey(method, path)
+	s.scripts[key] = append(s.scripts[key], rs...)
+}
+
+// Handle sets the response served for method and path once its script runs
+// out.
+func (s *Server) Handle(method, path string, r Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[routeKey(method, path)] = r
+}
+
+// ExpireToken invalidates the issued token, so the next authenticated request
+// gets a 401 until the client logs in again.
+func (s *Server) ExpireToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+func (s *Server) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+// Requests returns every non-login request received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	if strings.HasSuffix(r.URL.Path, "/auth/login") && r.Method == http.MethodPost {
+		s.login(w, r, body)
+		return
+	}
+
+	s.mu.Lock()
+	auth := r.Header.Get(s.authName)
+	s.requests = append(s.requests, Request{
+		Method:        r.Method,
+		Path:          r.URL.Path,
+		Query:         r.URL.RawQuery,
+		Authorization: auth,
+		Body:          body,
+	})
+	authorized := s.token != "" && auth == token.Value(s.authScheme, s.token)
+
+	key := routeKey(r.Method, r.URL.Path)
+	resp, ok := Unauthorized(), false
+	if authorized {
+		if q := s.scripts[key]; len(q) > 0 {
+			resp, s.scripts[key], ok = q[0], q[1:], true
+		} else {
+			resp, ok = s.handlers[key]
+		}
+		if !ok {
+			resp = JSON(http.StatusNotFound, map[string]any{"ok": false, "error": "not found"})
+		}
+	}
+	s.mu.Unlock()
+
+	write(w, resp)
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request, body []byte) {
+	var creds struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+		_ = r.ParseForm()
+		creds.Username, creds.Password = r.PostForm.Get("username"), r.PostForm.Get("password")
+	} else if err := json.Unmarshal(body, &creds); err != nil {
+		write(w, JSON(http.StatusBadRequest, map[string]any{"ok": false, "error": err.Error()}))
+		return
+	}
+
+	if creds.Username != s.username || creds.Password != s.password {
+		write(w, JSON(http.StatusUnauthorized, map[string]any{"ok": false, "error": "invalid credentials"}))
+		return
+	}
+
+	s.mu.Lock()
+	s.logins++
+	s.seq++
+	s.token = fmt.Sprintf("token-%d", s.seq)
+	tok := s.token
+	s.mu.Unlock()
+
+	write(w, JSON(http.StatusOK, map[string]any{"ok": true, "token": tok}))
+}
+
+func write(w http.ResponseWriter, r Response) {
+	for k, v := range r.Header {
+		w.Header()[k] = v
+	}
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+	w.WriteHeader(r.Status)
+	_, _ = io.WriteString(w, r.Body)
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
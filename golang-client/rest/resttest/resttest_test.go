@@ -0,0 +1,125 @@
+package resttest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest"
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+var noServers = resttest.JSON(http.StatusOK, map[string]any{"data": []any{}})
+
+func newClient(t *testing.T, srv *resttest.Server, cfg rest.Config) *rest.Client {
+	t.Helper()
+	cfg.BaseURL, cfg.Username, cfg.Password = srv.URL, "u", "p"
+	c, err := rest.NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestUnauthorizedThenOK(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	srv.Script(http.MethodGet, "/api/servers", resttest.Unauthorized())
+	srv.Handle(http.MethodGet, "/api/servers", noServers)
+	c := newClient(t, srv, rest.Config{})
+
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.Logins(); n != 2 {
+		t.Errorf("logins = %d, want 2", n)
+	}
+}
+
+func TestExpireToken(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	srv.Handle(http.MethodGet, "/api/servers", noServers)
+	c := newClient(t, srv, rest.Config{})
+
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv.ExpireToken()
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 3 || reqs[1].Authorization != "Bearer token-1" || reqs[2].Authorization != "Bearer token-2" {
+		t.Errorf("requests = %+v, want a retry on the new token", reqs)
+	}
+}
+
+func TestSetAuthHeader(t *testing.T) {
+	tests := []struct {
+		name, header, scheme string
+		want                 string
+	}{
+		{"bare token", "X-Api-Token", "", "token-1"},
+		{"custom scheme", "X-Api-Token", "Token", "Token token-1"},
+		{"default header", "", "JWT", "JWT token-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := resttest.NewServer("u", "p")
+			defer srv.Close()
+			srv.SetAuthHeader(tt.header, tt.scheme)
+			srv.Handle(http.MethodGet, "/api/servers", noServers)
+			c := newClient(t, srv, rest.Config{AuthHeaderName: tt.header, AuthScheme: tt.scheme})
+
+			if _, err := c.ListServers(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got := srv.Requests()[0].Authorization; got != tt.want {
+				t.Errorf("auth header = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetAuthHeaderMismatch(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	srv.SetAuthHeader("X-Api-Token", "")
+	srv.Handle(http.MethodGet, "/api/servers", noServers)
+	c := newClient(t, srv, rest.Config{})
+
+	if _, err := c.ListServers(context.Background()); rest.StatusCode(err) != http.StatusUnauthorized {
+		t.Fatalf("err = %v, want a 401", err)
+	}
+}
+
+func TestTooManyRequestsSurfaces(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	srv.Script(http.MethodGet, "/api/servers", resttest.TooManyRequests(2*time.Second))
+	srv.Handle(http.MethodGet, "/api/servers", noServers)
+	c := newClient(t, srv, rest.Config{})
+
+	_, err := c.ListServers(context.Background())
+	var apiErr *rest.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want a 429 *APIError", err)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("%d requests, want 1: the client does not retry 429", n)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	srv.Script(http.MethodGet, "/api/servers", resttest.Malformed(http.StatusOK))
+	c := newClient(t, srv, rest.Config{})
+
+	if _, err := c.ListServers(context.Background()); err == nil {
+		t.Fatal("no error for a truncated body")
+	}
+}
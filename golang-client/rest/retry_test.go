@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestRetry(t *testing.T) {
+	unavailable := resttest.JSON(http.StatusServiceUnavailable, map[string]any{"ok": false})
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		method     string
+		script     []resttest.Response
+		wantStatus int
+		wantSent   int
+	}{
+		{
+			name:     "recovers",
+			policy:   RetryPolicy{MaxAttempts: 3},
+			method:   http.MethodGet,
+			script:   []resttest.Response{unavailable, resttest.JSON(http.StatusBadGateway, nil)},
+			wantSent: 3,
+		},
+		{
+			name:       "gives up",
+			policy:     RetryPolicy{MaxAttempts: 2},
+			method:     http.MethodGet,
+			script:     []resttest.Response{unavailable, unavailable},
+			wantStatus: http.StatusServiceUnavailable,
+			wantSent:   2,
+		},
+		{
+			name:       "disabled",
+			method:     http.MethodGet,
+			script:     []resttest.Response{unavailable},
+			wantStatus: http.StatusServiceUnavailable,
+			wantSent:   1,
+		},
+		{
+			name:       "not transient",
+			policy:     RetryPolicy{MaxAttempts: 3},
+			method:     http.MethodGet,
+			script:     []resttest.Response{resttest.JSON(http.StatusInternalServerError, nil)},
+			wantStatus: http.StatusInternalServerError,
+			wantSent:   1,
+		},
+		{
+			name:       "post not retried",
+			policy:     RetryPolicy{MaxAttempts: 3},
+			method:     http.MethodPost,
+			script:     []resttest.Response{unavailable},
+			wantStatus: http.StatusServiceUnavailable,
+			wantSent:   1,
+		},
+		{
+			name:     "post retried when non-idempotent allowed",
+			policy:   RetryPolicy{MaxAttempts: 3, NonIdempotent: true},
+			method:   http.MethodPost,
+			script:   []resttest.Response{unavailable},
+			wantSent: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := resttest.NewServer("u", "p")
+			defer srv.Close()
+			tt.policy.BaseDelay = time.Millisecond
+			c, err := NewClientFromConfig(Config{BaseURL: srv.URL, Username: "u", Password: "p", Retry: tt.policy})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			if tt.method == http.MethodGet {
+				srv.Script(http.MethodGet, "/api/servers", tt.script...)
+				srv.Handle(http.MethodGet, "/api/servers", resttest.JSON(http.StatusOK, map[string]any{"data": []any{}}))
+				_, err = c.ListServers(ctx)
+			} else {
+				srv.Script(http.MethodPost, "/api/servers/srv/interfaces", tt.script...)
+				srv.Handle(http.MethodPost, "/api/servers/srv/interfaces", resttest.JSON(http.StatusOK, map[string]any{"ok": true}))
+				_, err = c.UpdateInterface(ctx, "srv", map[string]any{"name": "wg0"})
+			}
+
+			if got := StatusCode(err); got != tt.wantStatus || tt.wantStatus == 0 && err != nil {
+				t.Errorf("err = %v, want status %d", err, tt.wantStatus)
+			}
+			if got := len(srv.Requests()); got != tt.wantSent {
+				t.Errorf("sent %d requests, want %d", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRetryReplaysBody(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+	c, err := NewClientFromConfig(Config{
+		BaseURL:  srv.URL,
+		Username: "u",
+		Password: "p",
+		Retry:    RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, NonIdempotent: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Script(http.MethodPost, "/api/servers/srv/interfaces", resttest.JSON(http.StatusServiceUnavailable, nil))
+	srv.Handle(http.MethodPost, "/api/servers/srv/interfaces", resttest.JSON(http.StatusOK, map[string]any{"ok": true}))
+
+	if _, err := c.UpdateInterface(context.Background(), "srv", map[string]any{"name": "wg0"}); err != nil {
+		t.Fatal(err)
+	}
+	reqs := srv.Requests()
+	if len(reqs) != 2 || string(reqs[0].Body) != string(reqs[1].Body) || len(reqs[1].Body) == 0 {
+		t.Errorf("retry did not resend the body: %q", reqs)
+	}
+}
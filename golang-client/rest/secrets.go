@@ -0,0 +1,43 @@
+package rest
+
+import "encoding/json"
+
+// MarshalJSON leaves out PrivateKey so users and peers can be logged or
+// passed on without leaking keys. Use User.WithSecrets or
+// PeerState.WithSecrets to serialize the key on purpose.
+func (p PeerState) MarshalJSON() ([]byte, error) {
+	p.PrivateKey = ""
+	return json.Marshal(secretPeer(p))
+}
+
+// WithSecrets returns p in a form that marshals including PrivateKey.
+func (p PeerState) WithSecrets() json.Marshaler {
+	return secretPeer(p)
+}
+
+// WithSecrets returns u in a form that marshals including the peers'
+// private keys.
+func (u User) WithSecrets() json.Marshaler {
+	return secretUser(u)
+}
+
+type secretPeer PeerState
+
+func (p secretPeer) MarshalJSON() ([]byte, error) {
+	type plain PeerState
+	return json.Marshal(plain(p))
+}
+
+type secretUser User
+
+func (u secretUser) MarshalJSON() ([]byte, error) {
+	type plain User
+	peers := make([]secretPeer, len(u.Peers))
+	for i, p := range u.Peers {
+		peers[i] = secretPeer(p)
+	}
+	return json.Marshal(struct {
+		plain
+		Peers []secretPeer `json:"peers,omitempty"`
+	}{plain(u), peers})
+}
@@ -1,41 +1,138 @@
 package rest
 
-func (c *Client) ListServers() ([]Server, error) {
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/creds"
+)
+
+func (c *Client) ListServers(ctx context.Context) ([]Server, error) {
+	return c.serverList.Get(ctx, creds.Key(ctx), c.listServers)
+}
+
+func (c *Client) listServers(ctx context.Context) ([]Server, error) {
 	var out struct {
 		Data []Server `json:"data"`
 	}
-	err := c.requestJSON("GET", "/servers", nil, nil, &out)
+	err := c.requestJSON(ctx, "GET", c.serversPath("/servers"), nil, nil, &out)
 	return out.Data, err
 }
 
-func (c *Client) GetServer(serverID string) (Server, error) {
+func (c *Client) ListServersPublic(ctx context.Context) ([]PublicServer, error) {
+	servers, err := c.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]PublicServer, 0, len(servers))
+	for _, s := range servers {
+		out = append(out, s.Public())
+	}
+	return out, nil
+}
+
+func (c *Client) ListAllInterfaces(ctx context.Context) ([]ServerInterface, error) {
+	servers, err := c.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []ServerInterface
+	for _, s := range servers {
+		for _, i := range s.Interfaces {
+			out = append(out, ServerInterface{ServerID: s.Name, Interface: i})
+		}
+	}
+	return out, nil
+}
+
+// DuplicateEndpoints groups interfaces by "endpoint:port" and keeps only the
+// groups shared by more than one interface. Interfaces without an endpoint
+// are ignored.
+func DuplicateEndpoints(ifaces []ServerInterface) map[string][]ServerInterface {
+	groups := make(map[string][]ServerInterface)
+	for _, si := range ifaces {
+		if si.Interface.Endpoint == "" {
+			continue
+		}
+		key := net.JoinHostPort(strings.ToLower(si.Interface.Endpoint), strconv.Itoa(si.Interface.Port))
+		groups[key] = append(groups[key], si)
+	}
+	for key, g := range groups {
+		if len(g) < 2 {
+			delete(groups, key)
+		}
+	}
+	return groups
+}
+
+func (c *Client) GetServer(ctx context.Context, serverID string) (Server, error) {
+	return c.serverByID.Get(ctx, creds.Key(ctx)+"/"+serverID, func(ctx context.Context) (Server, error) {
+		return c.getServer(ctx, serverID)
+	})
+}
+
+func (c *Client) getServer(ctx context.Context, serverID string) (Server, error) {
 	var out struct {
 		Data Server `json:"data"`
 	}
-	err := c.requestJSON("GET", "/servers/"+serverID, nil, nil, &out)
+	err := c.requestJSON(ctx, "GET", c.serversPath("/servers/"+serverID), nil, nil, &out)
 	return out.Data, err
 }
 
-func (c *Client) CreateOrUpdateServerRaw(payload map[string]any) (map[string]any, error) {
+// CreateOrUpdateServerRaw upserts a server from a raw payload and returns
+// the stored server along with the raw response. The API answers with the
+// server's name only, so unless the response embeds the server under "data"
+// it is read back, picking up generated fields such as interface public
+// keys. The server is zero when the response names none.
+func (c *Client) CreateOrUpdateServerRaw(ctx context.Context, payload map[string]any) (Server, map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("POST", "/servers", nil, payload, &out)
-	return out, err
+	err := c.requestJSON(ctx, "POST", c.serversPath("/servers"), nil, payload, &out)
+	c.purgeServers()
+	if err != nil {
+		return Server{}, out, err
+	}
+
+	if data, ok := out["data"].(map[string]any); ok {
+		var srv Server
+		b, err := json.Marshal(data)
+		if err != nil {
+			return Server{}, out, err
+		}
+		return srv, out, json.Unmarshal(b, &srv)
+	}
+	name, _ := out["name"].(string)
+	if name == "" {
+		return Server{}, out, nil
+	}
+	srv, err := c.getServer(ctx, name)
+	return srv, out, err
 }
 
-func (c *Client) DeleteServer(serverID string) (map[string]any, error) {
+func (c *Client) DeleteServer(ctx context.Context, serverID string) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("DELETE", "/servers/"+serverID, nil, nil, &out)
+	err := c.requestJSON(ctx, "DELETE", c.serversPath("/servers/"+serverID), nil, nil, &out)
+	c.purgeServers()
 	return out, err
 }
 
-func (c *Client) UpdateInterface(serverID string, payload map[string]any) (map[string]any, error) {
+func (c *Client) UpdateInterface(ctx context.Context, serverID string, payload map[string]any) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("POST", "/servers/"+serverID+"/interfaces", nil, payload, &out)
+	err := c.requestJSON(ctx, "POST", c.serversPath("/servers/"+serverID+"/interfaces"), nil, payload, &out)
+	c.purgeServers()
 	return out, err
 }
 
-func (c *Client) DeleteInterface(serverID, ifaceName string) (map[string]any, error) {
+func (c *Client) DeleteInterface(ctx context.Context, serverID, ifaceName string) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("DELETE", "/servers/"+serverID+"/interfaces/"+ifaceName, nil, nil, &out)
+	err := c.requestJSON(ctx, "DELETE", c.serversPath("/servers/"+serverID+"/interfaces/"+ifaceName), nil, nil, &out)
+	c.purgeServers()
 	return out, err
 }
+
+func (c *Client) purgeServers() {
+	c.serverList.Purge()
+	c.serverByID.Purge()
+}
@@ -0,0 +1,125 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+// tenantServer logs in any user with password "pw" and lists one server
+// named after the user the token was issued to.
+func tenantServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			var in struct{ Username, Password string }
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			if in.Password != "pw" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": "tok-" + in.Username})
+			return
+		}
+		user, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer tok-")
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		srv := map[string]any{"name": "srv-" + user, "address": "10.0.0.1", "username": user}
+		if r.URL.Path == "/api/servers" {
+			json.NewEncoder(w).Encode(map[string]any{"data": []any{srv}})
+		} else {
+			json.NewEncoder(w).Encode(map[string]any{"data": srv})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestReadCacheIsPerCredentialSet(t *testing.T) {
+	srv := tenantServer(t)
+	c, err := NewClientFromConfig(Config{
+		BaseURL:        srv.URL,
+		Username:       "admin",
+		Password:       "pw",
+		ReadCacheFresh: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, user := range []string{"admin", "alice", "bob", "alice"} {
+		ctx := context.Background()
+		if user != "admin" {
+			ctx = WithCredentials(ctx, user, "pw")
+		}
+		list, err := c.ListServers(ctx)
+		if err != nil {
+			t.Fatalf("%s: ListServers: %v", user, err)
+		}
+		if len(list) != 1 || list[0].Name != "srv-"+user {
+			t.Errorf("%s: ListServers = %+v", user, list)
+		}
+		s, err := c.GetServer(ctx, "x")
+		if err != nil {
+			t.Fatalf("%s: GetServer: %v", user, err)
+		}
+		if s.Name != "srv-"+user {
+			t.Errorf("%s: GetServer = %s", user, s.Name)
+		}
+	}
+}
+
+func TestListAllInterfaces(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodGet, "/api/servers", resttest.JSON(http.StatusOK, map[string]any{"data": []Server{
+		{Name: "a", Interfaces: []WireGuardInterface{{Name: "wg0"}, {Name: "wg1"}}},
+		{Name: "b"},
+		{Name: "c", Interfaces: []WireGuardInterface{{Name: "wg0"}}},
+	}}))
+
+	got, err := c.ListAllInterfaces(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, si := range got {
+		keys = append(keys, si.ServerID+"/"+si.Interface.Name)
+	}
+	if want := []string{"a/wg0", "a/wg1", "c/wg0"}; !slices.Equal(keys, want) {
+		t.Errorf("interfaces = %v, want %v", keys, want)
+	}
+}
+
+func TestDuplicateEndpoints(t *testing.T) {
+	si := func(server, endpoint string, port int) ServerInterface {
+		return ServerInterface{ServerID: server, Interface: WireGuardInterface{Name: "wg0", Endpoint: endpoint, Port: port}}
+	}
+	got := DuplicateEndpoints([]ServerInterface{
+		si("a", "vpn.example.com", 51820),
+		si("b", "VPN.example.com", 51820),
+		si("c", "vpn.example.com", 51821),
+		si("d", "", 51820),
+		si("e", "", 51820),
+		si("f", "::1", 51820),
+		si("g", "::1", 51820),
+	})
+	if len(got) != 2 {
+		t.Fatalf("got %d groups, want 2: %v", len(got), got)
+	}
+	if g := got["vpn.example.com:51820"]; len(g) != 2 || g[0].ServerID != "a" || g[1].ServerID != "b" {
+		t.Errorf("vpn.example.com:51820 = %v, want a and b", g)
+	}
+	if g := got["[::1]:51820"]; len(g) != 2 {
+		t.Errorf("[::1]:51820 = %v, want f and g", g)
+	}
+}
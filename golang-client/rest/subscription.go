@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/sublink"
+)
+
+// SubscriptionURL returns the user's public subscription link under base,
+// the API root such as "https://panel.example.com/api". It fails with
+// ErrNoSubToken when the user has no token.
+func (u User) SubscriptionURL(base string) (string, error) {
+	if u.SubToken == "" {
+		return "", fmt.Errorf("%w: %s", ErrNoSubToken, u.UserKey)
+	}
+	return sublink.Link(base, u.SubToken), nil
+}
+
+// SubscriptionURL fetches the user and returns their subscription link on
+// this client's server.
+func (c *Client) SubscriptionURL(ctx context.Context, userKey string) (string, error) {
+	u, err := c.GetUser(ctx, userKey)
+	if err != nil {
+		return "", err
+	}
+	return u.SubscriptionURL(c.baseURL + c.cfg.BasePath)
+}
+
+// subscriptionURL returns the public /sub link for tok, or "" without one.
+func (c *Client) subscriptionURL(tok string) string {
+	if tok == "" {
+		return ""
+	}
+	return sublink.Link(c.baseURL+c.cfg.BasePath, tok)
+}
@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestUserSubscriptionURL(t *testing.T) {
+	tests := []struct {
+		name, base, tok, want string
+		wantErr               error
+	}{
+		{"plain", "https://panel.example.com/api", "abc123", "https://panel.example.com/api/sub/abc123", nil},
+		{"trailing slash", "https://panel.example.com/api/", "abc123", "https://panel.example.com/api/sub/abc123", nil},
+		{"escaped", "https://panel.example.com/api", "a/b c?d#e", "https://panel.example.com/api/sub/a%2Fb%20c%3Fd%23e", nil},
+		{"no token", "https://panel.example.com/api", "", "", ErrNoSubToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := User{UserKey: "k", SubToken: tt.tok}.SubscriptionURL(tt.base)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("SubscriptionURL() = %q, %v; want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClientSubscriptionURL(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodGet, "/api/users/k", resttest.JSON(http.StatusOK, map[string]any{"result": User{UserKey: "k", SubToken: "t/1"}}))
+
+	got, err := c.SubscriptionURL(context.Background(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := srv.URL + "/api/sub/t%2F1"; got != want {
+		t.Errorf("SubscriptionURL = %q, want %q", got, want)
+	}
+}
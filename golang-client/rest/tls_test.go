@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tlsServer is a backend behind a self-signed certificate, returned with a
+// pool trusting it.
+func tlsServer(t *testing.T) (*httptest.Server, *x509.CertPool) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": "tok"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	// rejected handshakes are expected
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	return srv, pool
+}
+
+func TestTLSOptions(t *testing.T) {
+	srv, pool := tlsServer(t)
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "system roots", wantErr: true},
+		{name: "root CAs", cfg: Config{RootCAs: pool}},
+		{name: "base config", cfg: Config{TLSConfig: &tls.Config{RootCAs: pool}}},
+		{name: "skip verify", cfg: Config{InsecureSkipVerify: true}},
+		{name: "restricted on top", cfg: Config{RootCAs: pool, MinTLSVersion: tls.VersionTLS13}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.BaseURL, cfg.Username, cfg.Password = srv.URL, "u", "p"
+			c, err := NewClientFromConfig(cfg)
+			if err == nil {
+				_, err = c.ListServers(context.Background())
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSConfigNotModified(t *testing.T) {
+	srv, _ := tlsServer(t)
+	base := &tls.Config{}
+	_, err := NewClientFromConfig(Config{
+		BaseURL:            srv.URL,
+		Username:           "u",
+		Password:           "p",
+		TLSConfig:          base,
+		InsecureSkipVerify: true,
+		MinTLSVersion:      tls.VersionTLS13,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base.InsecureSkipVerify || base.MinVersion != 0 {
+		t.Errorf("caller's TLSConfig was modified: %+v", base)
+	}
+}
@@ -1,13 +1,41 @@
 package rest
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+
+	"github.com/wyronapp/wyron-public/golang-client/internal/retry"
+	"github.com/wyronapp/wyron-public/golang-client/internal/sublink"
+	"github.com/wyronapp/wyron-public/golang-client/internal/wgconfig"
 )
 
 var (
 	ErrInterfaceNotFound   = errors.New("interface not found")
 	ErrInterfaceMissingKey = errors.New("interface missing key")
+	ErrServerNoInterfaces  = errors.New("server has no interfaces")
+	ErrServerMissing       = errors.New("server missing")
+	ErrUserNoPeers         = errors.New("user has no peers")
+	ErrNoSubToken          = sublink.ErrNoToken
+	ErrUnsupported         = errors.New("not supported by the server")
+	ErrAuthFailed          = errors.New("authentication failed")
+	ErrMissingCredentials  = errors.New("username and password required")
+	ErrUnavailable         = errors.New("server unavailable")
+	ErrUnauthenticated     = errors.New("token rejected")
+	ErrLoginNoToken        = errors.New("login succeeded without a token")
+	ErrTokenNotJWT         = errors.New("token is not a JWT")
+	ErrAuthLoop            = errors.New("too many re-logins")
+	ErrAccountDisabled     = errors.New("account disabled")
+	ErrUnknownMetric       = errors.New("unknown metric")
+	ErrConflict            = errors.New("conflicting update")
+	ErrInvalidKey          = errors.New("invalid key")
+	ErrInvalidAddress      = errors.New("invalid address")
+	ErrInvalidOption       = errors.New("invalid config option")
+	ErrInvalidStatus       = errors.New("invalid user status")
+	ErrIncompatibleServer  = errors.New("incompatible server version")
+	ErrUnknownVersion      = errors.New("server version unknown")
+	ErrActionFailed        = errors.New("action failed")
+	ErrResponseTooLarge    = errors.New("response too large")
 )
 
 type WireGuardInterface struct {
@@ -19,6 +47,12 @@ type WireGuardInterface struct {
 	Port        int    `json:"port,omitempty"`
 	CreatedAt   int64  `json:"created_at,omitempty"`
 	PublicKey   string `json:"public_key,omitempty"`
+
+	// AllowedIPs is the interface's recommended routing, e.g. split-tunnel
+	// subnets, when the backend provides one. Configs use it unless
+	// ConfigOptions.AllowedIPs is set, and route everything without either.
+	// Config generation fails on an entry that is not a CIDR prefix.
+	AllowedIPs []string `json:"allowed_ips,omitempty"`
 }
 
 type Server struct {
@@ -28,6 +62,52 @@ type Server struct {
 	DisplayName string               `json:"display_name,omitempty"`
 	CreatedAt   int64                `json:"created_at,omitempty"`
 	Interfaces  []WireGuardInterface `json:"interfaces,omitempty"`
+
+	// Region, Country and Flag are not part of the current API schema and
+	// stay empty unless the backend adds them. Metadata keeps any other
+	// fields the backend sends that Server doesn't model.
+	Region   string         `json:"region,omitempty"`
+	Country  string         `json:"country,omitempty"`
+	Flag     string         `json:"flag,omitempty"`
+	Metadata map[string]any `json:"-"`
+}
+
+type ServerInterface struct {
+	ServerID  string             `json:"server_id"`
+	Interface WireGuardInterface `json:"interface"`
+}
+
+// PublicServer is the subset of Server that is safe to show to end users.
+type PublicServer struct {
+	Name        string            `json:"name"`
+	DisplayName string            `json:"display_name,omitempty"`
+	Region      string            `json:"region,omitempty"`
+	Country     string            `json:"country,omitempty"`
+	Flag        string            `json:"flag,omitempty"`
+	Interfaces  []PublicInterface `json:"interfaces,omitempty"`
+}
+
+type PublicInterface struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+func (s Server) Public() PublicServer {
+	ifaces := make([]PublicInterface, 0, len(s.Interfaces))
+	for _, i := range s.Interfaces {
+		ifaces = append(ifaces, PublicInterface{
+			Name:        i.Name,
+			DisplayName: i.DisplayName,
+		})
+	}
+	return PublicServer{
+		Name:        s.Name,
+		DisplayName: s.DisplayName,
+		Region:      s.Region,
+		Country:     s.Country,
+		Flag:        s.Flag,
+		Interfaces:  ifaces,
+	}
 }
 
 type User struct {
@@ -45,6 +125,67 @@ type User struct {
 	Peers            []PeerState `json:"peers,omitempty"`
 }
 
+// ConfigOptions tunes the generated wg-quick config; it is shared with the
+// other transport so both render the same file.
+type ConfigOptions = wgconfig.Options
+
+// RetryPolicy configures Config.Retry; it is shared with the other
+// transport.
+type RetryPolicy = retry.Policy
+
+type ActionResult struct {
+	Success     bool
+	Message     string
+	AffectedKey string
+
+	// StatusCode is the HTTP status of the response. The Wyron API answers
+	// the user actions (delete, enable, disable, reset-usage) with 200; a 202
+	// from a proxy or newer backend means the change is still being applied
+	// and a 204 carries no body, which counts as success.
+	StatusCode int
+}
+
+func (r *ActionResult) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		OK      *bool  `json:"ok"`
+		Success *bool  `json:"success"`
+		Message string `json:"message"`
+		Error   string `json:"error"`
+		Details string `json:"details"`
+		Name    string `json:"name"`
+		UserKey string `json:"user_key"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	// a 2xx body without an explicit flag is a success
+	r.Success = true
+	if raw.OK != nil {
+		r.Success = *raw.OK
+	} else if raw.Success != nil {
+		r.Success = *raw.Success
+	}
+
+	r.Message = raw.Message
+	if r.Message == "" {
+		r.Message = raw.Error
+	}
+	if r.Message == "" {
+		r.Message = raw.Details
+	}
+
+	r.AffectedKey = raw.Name
+	if r.AffectedKey == "" {
+		r.AffectedKey = raw.UserKey
+	}
+	return nil
+}
+
+// Peer is a peer resolved against its interface, as produced by
+// NormalizePeer; it is the same type for both transports.
+type Peer = wgconfig.Peer
+
 type PeerState struct {
 	ServerID       string `json:"server_id"`
 	Interface      string `json:"interface"`
@@ -52,9 +193,42 @@ type PeerState struct {
 	PrivateKey     string `json:"private_key,omitempty"`
 }
 
+// Addresses splits AllowedAddress, which holds one address per family on a
+// dual-stack peer, e.g. "10.0.0.2/32, fd00::2/128".
+func (p PeerState) Addresses() ([]string, error) {
+	addrs, err := wgconfig.ParseAddresses(p.AllowedAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	return addrs, nil
+}
+
 func (p PeerState) GenerateConfig(srv *Server) (string, error) {
+	return p.GenerateConfigWithOptions(srv, ConfigOptions{})
+}
+
+func (p PeerState) GenerateConfigWithOptions(srv *Server, opts ConfigOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidOption, err)
+	}
+	peer, err := NormalizePeer(p, srv)
+	if err != nil {
+		return "", err
+	}
+	return wgconfig.Render(peer, opts), nil
+}
+
+// NormalizePeer resolves p against srv into the transport-agnostic shape
+// configs are rendered from.
+func NormalizePeer(p PeerState, srv *Server) (Peer, error) {
 	if p.PrivateKey == "" {
-		return "", ErrInterfaceMissingKey
+		return Peer{}, ErrInterfaceMissingKey
+	}
+	if srv == nil {
+		return Peer{}, fmt.Errorf("%w: %s", ErrServerMissing, p.ServerID)
+	}
+	if len(srv.Interfaces) == 0 {
+		return Peer{}, fmt.Errorf("%w: %s", ErrServerNoInterfaces, srv.Name)
 	}
 
 	var iface *WireGuardInterface
@@ -64,21 +238,35 @@ func (p PeerState) GenerateConfig(srv *Server) (string, error) {
 			break
 		}
 	}
-	if iface == nil {
-		return "", fmt.Errorf("%w: %s", ErrInterfaceNotFound, p.Interface)
-	}
-	if iface.Endpoint == "" || iface.PublicKey == "" || iface.Port == 0 {
-		return "", ErrInterfaceMissingKey
+	switch {
+	case iface == nil:
+		return Peer{}, fmt.Errorf("%w: %s on server %s", ErrInterfaceNotFound, p.Interface, srv.Name)
+	case iface.Endpoint == "":
+		return Peer{}, fmt.Errorf("%w: endpoint missing", ErrInterfaceMissingKey)
+	case iface.PublicKey == "":
+		return Peer{}, fmt.Errorf("%w: public_key missing", ErrInterfaceMissingKey)
+	case iface.Port == 0:
+		return Peer{}, fmt.Errorf("%w: port missing", ErrInterfaceMissingKey)
 	}
 
-	return fmt.Sprintf(`[Interface]
-Address = %s
-DNS = %s
-PrivateKey = %s
+	addrs, err := p.Addresses()
+	if err != nil {
+		return Peer{}, err
+	}
+	allowed, err := wgconfig.ParseAllowedIPs(iface.AllowedIPs)
+	if err != nil {
+		return Peer{}, fmt.Errorf("interface %s: %w", iface.Name, err)
+	}
 
-[Peer]
-AllowedIPs = 0.0.0.0/0
-Endpoint = %s:%d
-PublicKey = %s
-`, p.AllowedAddress, iface.DNS, p.PrivateKey, iface.Endpoint, iface.Port, iface.PublicKey), nil
+	return Peer{
+		ServerID:   p.ServerID,
+		Interface:  p.Interface,
+		Addresses:  addrs,
+		PrivateKey: p.PrivateKey,
+		DNS:        iface.DNS,
+		Endpoint:   iface.Endpoint,
+		Port:       iface.Port,
+		PublicKey:  iface.PublicKey,
+		AllowedIPs: allowed,
+	}, nil
 }
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name, ua, want string
+	}{
+		{"default", "", "wyron-go-client"},
+		{"custom", "billing-bot/1.2", "billing-bot/1.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var got []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				got = append(got, r.UserAgent())
+				mu.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				if strings.HasSuffix(r.URL.Path, "/auth/login") {
+					json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": "tok"})
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+			}))
+			defer srv.Close()
+
+			c, err := NewClientFromConfig(Config{BaseURL: srv.URL, Username: "u", Password: "p", UserAgent: tt.ua})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.ListServers(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(got) != 2 {
+				t.Fatalf("got %d requests, want the login and the call", len(got))
+			}
+			for _, ua := range got {
+				if ua != tt.want && !strings.HasPrefix(ua, tt.want+"/") {
+					t.Errorf("User-Agent = %q, want %q", ua, tt.want)
+				}
+			}
+		})
+	}
+}
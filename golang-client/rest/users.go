@@ -1,29 +1,123 @@
 package rest
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
+// maxPageSize is the largest limit the API accepts for a user listing.
+const maxPageSize = 100
+
 type ListUsersOptions struct {
 	SocialID *int64
-	Status   string
-	Search   string
-	Limit    int
-	Skip     int
-	Sort     string
-	Order    string
+
+	// Status filters by one of the UserStatus wire values; anything else
+	// fails with ErrInvalidStatus before a request is made.
+	Status string
+
+	Search string
+	Limit  int
+	Skip   int
+	Sort   string
+	Order  string
+
+	// SortBy and OrderBy take precedence over the raw Sort and Order strings.
+	SortBy  SortColumn
+	OrderBy SortOrder
+
+	// Fields asks for only the named user fields (wire names, e.g. "user_key",
+	// "usage"), leaving the rest zero; peers and sub_token are the big ones
+	// to omit. It is sent as the fields query parameter, which the current
+	// Wyron API ignores, so full users come back until the backend honors it.
+	Fields []string
+}
+
+type SortColumn string
+
+const (
+	SortByCreatedAt SortColumn = "created_at"
+	SortByUsage     SortColumn = "usage"
+	SortByUserKey   SortColumn = "user_key"
+)
+
+type SortOrder string
+
+const (
+	OrderAsc  SortOrder = "asc"
+	OrderDesc SortOrder = "desc"
+)
+
+func (c *Client) ListUsers(ctx context.Context, opt ListUsersOptions) ([]User, error) {
+	if err := opt.validate(); err != nil {
+		return nil, err
+	}
+	var out struct {
+		Result []User `json:"result"`
+	}
+	err := c.requestJSON(ctx, "GET", c.usersPath("/users"), opt.query(), nil, &out)
+	return out.Result, err
 }
 
-func (c *Client) ListUsers(opt ListUsersOptions) ([]User, error) {
+// ListUsersWithCount is ListUsers that also returns how many users match
+// opt across all pages, taken from X-Total-Count or the count or total field
+// of the response, or -1 when the server sends none of them.
+func (c *Client) ListUsersWithCount(ctx context.Context, opt ListUsersOptions) ([]User, int, error) {
+	if err := opt.validate(); err != nil {
+		return nil, -1, err
+	}
+	var out usersPage
+	resp, err := c.do(ctx, "GET", c.usersPath("/users"), opt.query(), nil, &out)
+	if err != nil {
+		return nil, -1, err
+	}
+	return out.Result, out.total(resp.Header), nil
+}
+
+type usersPage struct {
+	Result []User `json:"result"`
+	Count  *int   `json:"count"`
+	Total  *int   `json:"total"`
+}
+
+func (p usersPage) total(h http.Header) int {
+	if n, err := strconv.Atoi(h.Get("X-Total-Count")); err == nil {
+		return n
+	}
+	if p.Count != nil {
+		return *p.Count
+	}
+	if p.Total != nil {
+		return *p.Total
+	}
+	return -1
+}
+
+func (opt ListUsersOptions) validate() error {
+	if opt.Status != "" {
+		return validateStatus(opt.Status)
+	}
+	return nil
+}
+
+func (opt ListUsersOptions) query() url.Values {
+	if opt.SortBy != "" {
+		opt.Sort = string(opt.SortBy)
+	}
+	if opt.OrderBy != "" {
+		opt.Order = string(opt.OrderBy)
+	}
 	if opt.Limit == 0 {
 		opt.Limit = 50
 	}
 	if opt.Sort == "" {
-		opt.Sort = "created_at"
+		opt.Sort = string(SortByCreatedAt)
 	}
 	if opt.Order == "" {
-		opt.Order = "desc"
+		opt.Order = string(OrderDesc)
 	}
 
 	q := url.Values{}
@@ -40,64 +134,180 @@ func (c *Client) ListUsers(opt ListUsersOptions) ([]User, error) {
 	if opt.Search != "" {
 		q.Set("search", opt.Search)
 	}
+	if len(opt.Fields) > 0 {
+		q.Set("fields", strings.Join(opt.Fields, ","))
+	}
+	return q
+}
 
+func (c *Client) GetUser(ctx context.Context, userID string) (User, error) {
 	var out struct {
-		Result []User `json:"result"`
+		Result User `json:"result"`
 	}
-	err := c.requestJSON("GET", "/users", q, nil, &out)
+	err := c.requestJSON(ctx, "GET", c.usersPath("/users/"+userID), nil, nil, &out)
 	return out.Result, err
 }
 
-func (c *Client) GetUser(userID string) (User, error) {
+// CreateUser checks params before sending them; a *ValidationError names
+// each field that is missing or out of range.
+func (c *Client) CreateUser(ctx context.Context, params CreateUserParams) (User, error) {
+	if err := validationError(params.Validate()); err != nil {
+		return User{}, err
+	}
+	return c.createUser(ctx, params)
+}
+
+// CreateUserRaw sends payload as is, for fields CreateUserParams doesn't
+// carry yet.
+func (c *Client) CreateUserRaw(ctx context.Context, payload map[string]any) (User, error) {
+	return c.createUser(ctx, payload)
+}
+
+// CreateUserWithParams is CreateUser under the name the gRPC client gives
+// its validated create.
+//
+// Deprecated: use CreateUser.
+func (c *Client) CreateUserWithParams(ctx context.Context, params CreateUserParams) (User, error) {
+	return c.CreateUser(ctx, params)
+}
+
+func (c *Client) createUser(ctx context.Context, body any) (User, error) {
 	var out struct {
 		Result User `json:"result"`
 	}
-	err := c.requestJSON("GET", "/users/"+userID, nil, nil, &out)
+	err := c.requestJSON(ctx, "POST", c.usersPath("/users"), nil, body, &out)
 	return out.Result, err
 }
 
-func (c *Client) CreateUser(payload map[string]any) (User, error) {
+// CreateUserWithConfig creates a user with access to interfaceName on
+// serverID, added to params.ServerAccess when missing, and renders that
+// peer's config. When the config can't be built the created user is still
+// returned with the error, so callers can finish or undo the onboarding.
+func (c *Client) CreateUserWithConfig(ctx context.Context, params CreateUserParams, serverID, interfaceName string, opts ConfigOptions) (User, string, error) {
+	params.ServerAccess = withAccess(params.ServerAccess, serverID, interfaceName)
+	user, err := c.CreateUser(ctx, params)
+	if err != nil {
+		return User{}, "", err
+	}
+	for _, p := range user.Peers {
+		if p.ServerID == serverID && p.Interface == interfaceName {
+			srv, err := c.GetServer(ctx, serverID)
+			if err != nil {
+				return user, "", err
+			}
+			conf, err := p.GenerateConfigWithOptions(&srv, opts)
+			return user, conf, err
+		}
+	}
+	return user, "", fmt.Errorf("%w: %s on server %s", ErrUserNoPeers, interfaceName, serverID)
+}
+
+func (c *Client) EditUser(ctx context.Context, userID string, payload map[string]any) (User, error) {
 	var out struct {
 		Result User `json:"result"`
 	}
-	err := c.requestJSON("POST", "/users", nil, payload, &out)
+	err := c.requestJSON(ctx, "PATCH", c.usersPath("/users/"+userID), nil, payload, &out)
 	return out.Result, err
 }
 
-func (c *Client) EditUser(userID string, payload map[string]any) (User, error) {
+func (c *Client) EditUserWithParams(ctx context.Context, userID string, params EditUserParams) (User, error) {
+	if err := validationError(params.Validate()); err != nil {
+		return User{}, err
+	}
 	var out struct {
 		Result User `json:"result"`
 	}
-	err := c.requestJSON("PATCH", "/users/"+userID, nil, payload, &out)
+	err := c.requestJSON(ctx, "PATCH", c.usersPath("/users/"+userID), nil, params, &out)
 	return out.Result, err
 }
 
-func (c *Client) DeleteUser(userID string) (map[string]any, error) {
+func (c *Client) DeleteUser(ctx context.Context, userID string) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("DELETE", "/users/"+userID, nil, nil, &out)
+	err := c.requestJSON(ctx, "DELETE", c.usersPath("/users/"+userID), nil, nil, &out)
 	return out, err
 }
 
-func (c *Client) EnableUser(userID string) (map[string]any, error) {
+func (c *Client) EnableUser(ctx context.Context, userID string) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("POST", "/users/"+userID+"/enable", nil, nil, &out)
+	err := c.requestJSON(ctx, "POST", c.usersPath("/users/"+userID+"/enable"), nil, nil, &out)
 	return out, err
 }
 
-func (c *Client) DisableUser(userID string) (map[string]any, error) {
+func (c *Client) DisableUser(ctx context.Context, userID string) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("POST", "/users/"+userID+"/disable", nil, nil, &out)
+	err := c.requestJSON(ctx, "POST", c.usersPath("/users/"+userID+"/disable"), nil, nil, &out)
 	return out, err
 }
 
-func (c *Client) ResetUsage(userID string) (map[string]any, error) {
+func (c *Client) ResetUsage(ctx context.Context, userID string) (map[string]any, error) {
 	var out map[string]any
-	err := c.requestJSON("POST", "/users/"+userID+"/reset-usage", nil, nil, &out)
+	err := c.requestJSON(ctx, "POST", c.usersPath("/users/"+userID+"/reset-usage"), nil, nil, &out)
 	return out, err
 }
 
-func (c *Client) Metrics() (map[string]any, error) {
-	var out map[string]any
-	err := c.requestJSON("GET", "/users/metrics", nil, nil, &out)
-	return out, err
+func (c *Client) DeleteUserResult(ctx context.Context, userID string) (ActionResult, error) {
+	return c.action(ctx, "DELETE", c.usersPath("/users/"+userID), userID)
+}
+
+func (c *Client) EnableUserResult(ctx context.Context, userID string) (ActionResult, error) {
+	return c.action(ctx, "POST", c.usersPath("/users/"+userID+"/enable"), userID)
+}
+
+func (c *Client) DisableUserResult(ctx context.Context, userID string) (ActionResult, error) {
+	return c.action(ctx, "POST", c.usersPath("/users/"+userID+"/disable"), userID)
+}
+
+func (c *Client) ResetUsageResult(ctx context.Context, userID string) (ActionResult, error) {
+	return c.action(ctx, "POST", c.usersPath("/users/"+userID+"/reset-usage"), userID)
+}
+
+func (c *Client) action(ctx context.Context, method, path, key string) (ActionResult, error) {
+	// an empty 2xx body, as with 204, leaves this untouched
+	out := ActionResult{Success: true}
+	resp, err := c.do(ctx, method, path, nil, nil, &out)
+	if resp != nil {
+		out.StatusCode = resp.StatusCode
+	}
+	if err != nil {
+		out.Success = false
+		return out, err
+	}
+	if out.AffectedKey == "" {
+		out.AffectedKey = key
+	}
+	if !out.Success {
+		return out, fmt.Errorf("%w: %s %s: %s", ErrActionFailed, method, path, out.Message)
+	}
+	return out, nil
+}
+
+// SetUserNote would attach a free-form note to a user. The Wyron API stores no
+// notes or labels, so it always fails with ErrUnsupported.
+func (c *Client) SetUserNote(ctx context.Context, userKey, note string) (string, error) {
+	return "", fmt.Errorf("%w: user notes for %q", ErrUnsupported, userKey)
+}
+
+// FindDuplicateSocialIDs pages through all users and returns the social IDs
+// shared by more than one user, with the keys of those users.
+func (c *Client) FindDuplicateSocialIDs(ctx context.Context) (map[int64][]string, error) {
+	keys := make(map[int64][]string)
+	it := c.UsersIterator(ListUsersOptions{Limit: maxPageSize, SortBy: SortByCreatedAt, OrderBy: OrderAsc})
+	for {
+		u, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		keys[u.SocialID] = append(keys[u.SocialID], u.UserKey)
+	}
+
+	out := make(map[int64][]string)
+	for id, ks := range keys {
+		if len(ks) > 1 {
+			out[id] = ks
+		}
+	}
+	return out, nil
 }
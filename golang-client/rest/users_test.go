@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func newTestClient(t *testing.T) (*resttest.Server, *Client) {
+	t.Helper()
+	srv := resttest.NewServer("u", "p")
+	t.Cleanup(srv.Close)
+	c, err := NewClientFromConfig(Config{BaseURL: srv.URL, Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, c
+}
+
+func TestCreateUserValidates(t *testing.T) {
+	srv, c := newTestClient(t)
+
+	_, err := c.CreateUser(context.Background(), CreateUserParams{TrafficLimit: -1})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("err = %v, want a *ValidationError", err)
+	}
+	fields := make(map[string]bool)
+	for _, fe := range ve.Errors {
+		fields[fe.Field] = true
+	}
+	if !fields[FieldTrafficLimit] || !fields[FieldSocialID] {
+		t.Errorf("fields = %v, want traffic_limit and social_id", fields)
+	}
+	if n := len(srv.Requests()); n != 0 {
+		t.Errorf("%d requests sent for invalid params", n)
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	srv, c := newTestClient(t)
+	srv.Handle(http.MethodPost, "/api/users", resttest.JSON(http.StatusOK, map[string]any{
+		"ok":     true,
+		"result": map[string]any{"user_key": "k1", "social_id": 7},
+	}))
+
+	u, err := c.CreateUser(context.Background(), CreateUserParams{SocialID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.UserKey != "k1" || u.SocialID != 7 {
+		t.Errorf("user = %+v", u)
+	}
+
+	u, err = c.CreateUserWithParams(context.Background(), CreateUserParams{SocialID: 7})
+	if err != nil || u.UserKey != "k1" {
+		t.Errorf("CreateUserWithParams = %+v, %v", u, err)
+	}
+	var ve *ValidationError
+	if _, err := c.CreateUserWithParams(context.Background(), CreateUserParams{}); !errors.As(err, &ve) {
+		t.Errorf("CreateUserWithParams with no social ID: err = %v, want a *ValidationError", err)
+	}
+}
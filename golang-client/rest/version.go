@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ServerVersion asks the backend for its version. Backends without a version
+// endpoint yield ErrUnsupported.
+func (c *Client) ServerVersion(ctx context.Context) (string, error) {
+	var out struct {
+		Version string `json:"version"`
+	}
+	resp, err := c.do(ctx, "GET", c.cfg.BasePath+"/version", nil, nil, &out)
+	if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed) {
+		return "", fmt.Errorf("%w: version endpoint", ErrUnsupported)
+	}
+	if err != nil {
+		return "", err
+	}
+	if out.Version == "" {
+		return "", fmt.Errorf("%w: version endpoint", ErrUnsupported)
+	}
+	return out.Version, nil
+}
+
+// checkServerVersion compares the backend's version with want, the parsed
+// MinServerVersion. A backend without a version endpoint, which the current
+// API doesn't define, fails with ErrUnknownVersion: the minimum can't
+// be verified.
+func (c *Client) checkServerVersion(ctx context.Context, want []int) error {
+	v, err := c.ServerVersion(ctx)
+	if errors.Is(err, ErrUnsupported) {
+		return fmt.Errorf("%w: need >= %s: %w", ErrUnknownVersion, c.cfg.MinServerVersion, err)
+	}
+	if err != nil {
+		return err
+	}
+
+	got, err := parseVersion(v)
+	if err != nil {
+		return fmt.Errorf("%w: unparsable server version %q", ErrIncompatibleServer, v)
+	}
+	if compareVersions(got, want) < 0 {
+		return fmt.Errorf("%w: server is %s, need >= %s", ErrIncompatibleServer, v, c.cfg.MinServerVersion)
+	}
+	return nil
+}
+
+// parseVersion reads "v1.2.3"-style versions; build and pre-release suffixes
+// are ignored.
+func parseVersion(v string) ([]int, error) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return nil, errors.New("empty version")
+	}
+
+	parts := strings.Split(v, ".")
+	out := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", v)
+		}
+		out[i] = n
+	}
+	return out, nil
+}
+
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/wyronapp/wyron-public/golang-client/rest/resttest"
+)
+
+func TestMinServerVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		min     string
+		version string // empty serves no version endpoint
+		wantErr error
+	}{
+		{"no endpoint", "1.2", "", ErrUnknownVersion},
+		{"newer", "1.2", "v1.10.0", nil},
+		{"equal", "1.2", "1.2.0", nil},
+		{"older", "1.2", "1.1.9", ErrIncompatibleServer},
+		{"unparsable", "1.2", "dev", ErrIncompatibleServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := resttest.NewServer("u", "p")
+			defer srv.Close()
+			if tt.version != "" {
+				srv.Handle(http.MethodGet, "/api/version", resttest.JSON(http.StatusOK, map[string]any{"version": tt.version}))
+			}
+
+			_, err := NewClientFromConfig(Config{BaseURL: srv.URL, Username: "u", Password: "p", MinServerVersion: tt.min})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInvalidMinServerVersionFailsBeforeIO(t *testing.T) {
+	srv := resttest.NewServer("u", "p")
+	defer srv.Close()
+
+	_, err := NewClientFromConfig(Config{BaseURL: srv.URL, Username: "u", Password: "p", MinServerVersion: "latest"})
+	if err == nil {
+		t.Fatal("no error for an invalid MinServerVersion")
+	}
+	if n := srv.Logins(); n != 0 {
+		t.Errorf("logged in %d times before rejecting the config", n)
+	}
+}
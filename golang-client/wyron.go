@@ -7,8 +7,16 @@ import (
 	"github.com/wyronapp/wyron-public/golang-client/rest"
 )
 
-func NewRestClient(baseURL, username, password string, timeout time.Duration) (*rest.Client, error) {
-	return rest.NewClient(baseURL, username, password, timeout)
+// NewRestClient connects over REST. proxyURL may be empty, or an http(s) or
+// socks5 proxy URL.
+func NewRestClient(baseURL, username, password, proxyURL string, timeout time.Duration) (*rest.Client, error) {
+	return rest.NewClient(baseURL, username, password, proxyURL, timeout)
+}
+
+// NewRestClientFromConfig connects over REST with the full set of options,
+// proxy included, like NewGRPCClient does.
+func NewRestClientFromConfig(cfg rest.Config) (*rest.Client, error) {
+	return rest.NewClientFromConfig(cfg)
 }
 
 func NewGRPCClient(cfg grpc.Config) (*grpc.Client, error) {
@@ -0,0 +1,91 @@
+package wyron_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewRestClientUsesProxy(t *testing.T) {
+	var mu sync.Mutex
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hosts = append(hosts, r.URL.Host)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": "tok"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer proxy.Close()
+
+	c, err := NewRestClient("http://wyron.invalid", "u", "p", proxy.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hosts) == 0 {
+		t.Fatal("no request went through the proxy")
+	}
+	for _, h := range hosts {
+		if h != "wyron.invalid" {
+			t.Errorf("proxied request for %q, want wyron.invalid", h)
+		}
+	}
+}
+
+func TestNewRestClientWithoutProxy(t *testing.T) {
+	var mu sync.Mutex
+	var uris []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		uris = append(uris, r.RequestURI)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/auth/login") {
+			json.NewEncoder(w).Encode(map[string]any{"ok": true, "token": "tok"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": []any{}})
+	}))
+	defer srv.Close()
+
+	c, err := NewRestClient(srv.URL, "u", "p", "", 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListServers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(uris) == 0 {
+		t.Fatal("no request reached the server")
+	}
+	// a proxy is sent the absolute URL, the server itself only the path
+	for _, uri := range uris {
+		if !strings.HasPrefix(uri, "/") {
+			t.Errorf("request for %q, want a path", uri)
+		}
+	}
+}
+
+func TestNewRestClientRejectsBadProxy(t *testing.T) {
+	if _, err := NewRestClient("http://wyron.invalid", "u", "p", "ftp://proxy.invalid", 0); err == nil {
+		t.Fatal("no error for an unsupported proxy scheme")
+	}
+}